@@ -0,0 +1,450 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	m2m "github.com/aatomu/model2minecraft"
+	"github.com/aatomu/model2minecraft/colormatch"
+	"github.com/aatomu/model2minecraft/export"
+	"github.com/aatomu/model2minecraft/mesh"
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/raster"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Configuration Area
+var (
+	// Output Configuration
+	sourceType       Source         = Object
+	outputDirectory  string         = "./output"
+	maxCommandChain  int            = 700000
+	colorDepthBit    int            = 8 // 1-8
+	commandGenerator export.Command = func(v voxel.Voxel) (cmd string) {
+		return fmt.Sprintf("setblock ~%.2f ~%.2f ~%.2f %s", v.Position.X, v.Position.Y, v.Position.Z, v.BlockID)
+		// return fmt.Sprintf("particle dust{color:[%ff,%ff,%ff],scale:0.2f} ~%.2f ~%.2f ~%.2f 0 0 0 0 1 force @a", float64(v.Color.R)/255, float64(v.Color.G)/255, float64(v.Color.B)/255, v.Position.X, v.Position.Y, v.Position.Z)
+	}
+	enableBlockCount bool = false
+	exportSchematic  bool = false // Also write Sponge .schem(WorldEdit) of each set
+	schematicVersion int  = 3953  // Minecraft data version of .schem, 3953: 1.21
+	dryRun           bool = false // Print dimensions and block counts, write nothing
+
+	// Level of detail Configuration
+	blockBudget  int  = 0     // Max blocks, models are rescaled(mesh) or decimated(others) to fit, 0: unlimited
+	removeHidden bool = false // Delete blocks enclosed by 6 neighbors, keep the visible shell
+
+	// Object Configuration
+	objectDirectory     string             = "./3d"
+	objectFilename      string             = "HatsuneMiku.obj"
+	objectTriangulation mesh.Triangulation = mesh.EarClipping // n-gon: EarClipping, Fan
+	objectScale         float64            = 9.0 / 5.0
+	objectGridSpacing   float64            = 1.0 / 1.0
+	objectTransform     mesh.Transform     = mesh.Transform{
+		ZUp:       false,               // Z-up(Blender/CAD) to Y-up
+		Rotation:  [3]float64{0, 0, 0}, // Euler degrees X→Y→Z
+		Mirror:    [3]bool{false, false, false},
+		FitHeight: 0,               // blocks, 0: off
+		FitWidth:  0,               // blocks, 0: off
+		Anchor:    mesh.AnchorNone, // AnchorNone, AnchorCenter, AnchorGround
+		Translate: [3]float64{0, 0, 0},
+	}
+	objectConnectivity       mesh.Connectivity  = mesh.Connectivity6 // Surface: Connectivity6(watertight), Connectivity26(thin)
+	isObjectUVYAxisUp        bool               = true
+	objectTextureFilter      mesh.TextureFilter = mesh.Nearest                          // Texture sampling: Nearest, Bilinear, AreaAverage(voxel footprint)
+	objectTextureWrap        mesh.WrapMode      = mesh.Repeat                           // UV outside 0..1: Repeat, Clamp, Mirror
+	objectTintTexture        bool               = false                                 // Multiply .obj map_Kd texture by Kd color, glTF baseColorFactor is always applied
+	objectVertexColorTexture bool               = false                                 // Multiply map_Kd texture by vertex colors(v x y z r g b), untextured faces always use vertex colors
+	objectDefaultColor       palette.Color      = palette.Color{R: 200, G: 200, B: 200} // Material without texture/Kd/Ka
+	objectFillMode           voxel.FillMode     = voxel.Hollow                          // Interior: Hollow, FloodFill, Parity
+	objectFillBlockId        string             = ""                                    // Interior block, "": nearest surface color
+	objectShellThickness     int                = 0                                     // Filled depth from surface, 0: fill all
+	parallelLimit            int                = 10
+
+	// STL Configuration (uses Object transform/grid/fill configuration)
+	stlFilename string = "./3d/model.stl"
+	stlBlockId  string = "" // Block of every facet, "": facet color or objectDefaultColor
+
+	// glTF Configuration (uses Object transform/grid/fill configuration)
+	gltfFilename string = "./3d/model.glb" // .gltf or .glb
+
+	// VOX Configuration (MagicaVoxel, one voxel to one block)
+	voxFilename string         = "./3d/model.vox"
+	voxBlockIds map[int]string = map[int]string{} // Palette index(1-255) to block id, e.g. {1: "stone"}
+
+	// Point cloud Configuration (uses Object scale/transform/grid configuration)
+	plyFilename       string = "./3d/scan.ply" // ASCII/binary, faces are sampled as surfaces when exist
+	xyzFilename       string = "./3d/scan.xyz" // "x y z [r g b]"
+	pointMinimumCount int    = 2               // Points needed for a voxel, fewer is noise
+
+	// Minecraft model Configuration (uses Object transform/grid/fill configuration)
+	modelName  string  = "block/anvil" // Model in minecraftDirectory, e.g. "item/diamond_sword"
+	modelScale float64 = 1.0           // Blocks per model pixel(1/16 block)
+
+	// Scene Configuration (sources composed into one build)
+	sceneSources []m2m.SceneSource = []m2m.SceneSource{
+		{
+			File: "./3d/pedestal.obj",
+			Configure: func(opt *m2m.Options) {
+				opt.ObjectTransform = mesh.Transform{Anchor: mesh.AnchorGround}
+				opt.ObjectFillMode = voxel.FloodFill
+			},
+			Offset:   voxel.Pos{X: 0, Y: 0, Z: 0},
+			Priority: 0,
+		},
+		{
+			File: "./3d/HatsuneMiku.obj",
+			Configure: func(opt *m2m.Options) {
+				opt.ObjectTransform = mesh.Transform{FitHeight: 48, Anchor: mesh.AnchorGround}
+			},
+			Offset:   voxel.Pos{X: 0, Y: 4, Z: 0},
+			Priority: 1, // Statue wins the overlapping top of the pedestal
+		},
+		{
+			File: "./banner.png",
+			Configure: func(opt *m2m.Options) {
+				opt.AllowedBlockIds = []string{"wool$", "concrete$"}
+			},
+			Offset:   voxel.Pos{X: -16, Y: 56, Z: -8},
+			Priority: 0,
+		},
+	}
+
+	// Image Configuration
+	imageFilename string        = "../develop/assets/cbw32.png"
+	imageResize   raster.Resize = raster.Resize{
+		Width:  0,                 // Target blocks, 0: by Height(both 0: original size)
+		Height: 0,                 // Target blocks, 0: by Width, both: fit inside keeping aspect
+		Filter: raster.Box,        // Nearest(pixel art), Box, Bilinear, Lanczos(photo)
+		Crop:   image.Rectangle{}, // Source pixel region, e.g. image.Rect(0, 0, 512, 512), empty: whole
+	}
+	imagePlacement raster.Placement = raster.Placement{
+		Orientation:    raster.WallSouth,    // WallSouth, WallNorth, WallEast, WallWest, Floor(map art), Ceiling
+		Rotation:       [3]float64{0, 0, 0}, // Euler degrees X→Y→Z after Orientation
+		FlipHorizontal: false,
+		FlipVertical:   false,
+	}
+
+	// Map art Configuration (XZ plane, 128×128 map tiles)
+	mapArtFilename string         = "./mapart.png"   // Uses imageResize, e.g. Width: 128
+	mapArtMode     m2m.MapArtMode = m2m.MapStaircase // MapFlat(1 shade), MapStaircase, MapValley(3 shades, lower)
+
+	// GIF Configuration (uses imageResize, imagePlacement and videoFrameRate)
+	gifFilename string = "./animation.gif"
+
+	// Video Configuration (*requires ffmpeg, uses imagePlacement)
+	videoFilename  string = "./minecraft/example.mp4"
+	videoFrameRate int    = 20
+	videoScaleSize string = "200:-1" // ffmpeg rescale argument
+
+	// Minecraft Configuration
+	minecraftDirectory string            = "./assets"
+	allowedBlockIds    []string          = []string{""}                                         // Allowed regex patterns
+	ignoredBlockIds    []string          = []string{"^powder_snow$", "glass", "spawner", "ice"} // Ignored regex patterns
+	blockFilterPresets []string          = []string{}                                           // Block meta presets: survival, renewable, non-flammable, no-tile-entities, no-light
+	mergePolicy        voxel.MergePolicy = voxel.FirstWins                                      // Overlapping samples: FirstWins, LastWins, MajorityVote, AverageColor, LabAverage, LargestArea, OutwardNormal
+	shading            voxel.Shading     = voxel.Shading{
+		Directional: false,                  // Light by face normal(mesh sources)
+		Direction:   [3]float64{-1, -2, -1}, // Direction the light travels
+		Ambient:     0.4,                    // Brightness of unlit faces 0..1
+		Occlusion:   false,                  // Ambient occlusion(all sources)
+		Radius:      2,                      // Occlusion search radius in voxels
+		Strength:    0.5,                    // Darkening of fully occluded voxels 0..1
+	}
+	blockPhysicsMode voxel.PhysicsMode = voxel.PhysicsSupport // Handling of gravity/support/fluid blocks
+	supportBlockId   string            = "stone"              // Block inserted by PhysicsSupport
+)
+
+// Supported file format
+type Source int
+
+const (
+	Object Source = iota // Supported .obj(using .mtl&.png)
+	Image                // Supported .png .jpeg
+	Video                // Supported .mp4
+	STL                  // Supported .stl(binary/ASCII)
+	GLTF                 // Supported .gltf .glb(glTF 2.0)
+	VOX                  // Supported .vox(MagicaVoxel)
+	PLY                  // Supported .ply(ASCII/binary point cloud or mesh)
+	XYZ                  // Supported .xyz(point cloud)
+	Model                // Supported Minecraft block/item model .json
+	Scene                // Composed sceneSources
+	MapArt               // Supported .png .jpeg to map art
+	GIF                  // Supported animated .gif
+)
+
+func main() {
+	start := time.Now()
+	converter, err := m2m.New(m2m.Options{
+		MinecraftDirectory:       minecraftDirectory,
+		AllowedBlockIds:          allowedBlockIds,
+		IgnoredBlockIds:          ignoredBlockIds,
+		BlockFilterPresets:       blockFilterPresets,
+		ColorDepthBit:            colorDepthBit,
+		ColorMetric:              colormatch.LabDistance,
+		MergePolicy:              mergePolicy,
+		Shading:                  shading,
+		PhysicsMode:              blockPhysicsMode,
+		SupportBlockId:           supportBlockId,
+		BlockBudget:              blockBudget,
+		RemoveHidden:             removeHidden,
+		ObjectDirectory:          objectDirectory,
+		ObjectTriangulation:      objectTriangulation,
+		ObjectScale:              objectScale,
+		ObjectTransform:          objectTransform,
+		ObjectGridSpacing:        objectGridSpacing,
+		ObjectConnectivity:       objectConnectivity,
+		IsObjectUVYAxisUp:        isObjectUVYAxisUp,
+		ObjectTextureFilter:      objectTextureFilter,
+		ObjectTextureWrap:        objectTextureWrap,
+		ObjectTintTexture:        objectTintTexture,
+		ObjectVertexColorTexture: objectVertexColorTexture,
+		ObjectDefaultColor:       objectDefaultColor,
+		ObjectFillMode:           objectFillMode,
+		ObjectFillBlockId:        objectFillBlockId,
+		ObjectShellThickness:     objectShellThickness,
+		STLBlockId:               stlBlockId,
+		VoxBlockIds:              voxBlockIds,
+		PointMinimumCount:        pointMinimumCount,
+		ModelScale:               modelScale,
+		ParallelLimit:            parallelLimit,
+		ImageResize:              imageResize,
+		ImagePlacement:           imagePlacement,
+		MapArtMode:               mapArtMode,
+		VideoFrameRate:           videoFrameRate,
+		VideoScaleSize:           videoScaleSize,
+		Log:                      os.Stdout,
+	})
+	if err == nil {
+		err = run(converter)
+		converter.Diagnostics().Print(os.Stdout)
+	}
+	if err != nil {
+		fmt.Printf("\nFailed program: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\nFinished program: %s\n", time.Since(start))
+}
+
+func run(converter *m2m.Converter) error {
+	var sets []*m2m.VoxelSet
+	switch sourceType {
+	case Object:
+		f, err := os.Open(filepath.Join(objectDirectory, objectFilename))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := converter.ConvertOBJ(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case STL:
+		f, err := os.Open(stlFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := converter.ConvertSTL(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case GLTF:
+		f, err := os.Open(gltfFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := converter.ConvertGLTF(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case VOX:
+		f, err := os.Open(voxFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := converter.ConvertVOX(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case PLY, XYZ:
+		filename, convert := plyFilename, converter.ConvertPLY
+		if sourceType == XYZ {
+			filename, convert = xyzFilename, converter.ConvertXYZ
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := convert(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case Model:
+		set, err := converter.ConvertModel(modelName)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case Scene:
+		set, err := converter.ConvertScene(sceneSources)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case Image:
+		f, err := os.Open(imageFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		set, err := converter.ConvertImage(f)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+
+	case MapArt:
+		f, err := os.Open(mapArtFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		tiles, err := converter.ConvertMapArt(f)
+		if err != nil {
+			return err
+		}
+		sets = tiles
+
+	case GIF:
+		f, err := os.Open(gifFilename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		frames, err := converter.ConvertGIF(f)
+		if err != nil {
+			return err
+		}
+		sets = frames
+
+	case Video:
+		frames, err := converter.ConvertVideo(videoFilename)
+		if err != nil {
+			return err
+		}
+		sets = frames
+	}
+
+	printSummary(sets)
+	if dryRun {
+		fmt.Printf("\nDry run, nothing is written\n")
+		return nil
+	}
+
+	fmt.Printf("\nCreate function...\n")
+	createStart := time.Now()
+	exporter := export.MCFunction{
+		Directory:       outputDirectory,
+		MaxCommandChain: maxCommandChain,
+		Generator:       commandGenerator,
+	}
+	schematic := export.Schematic{
+		Directory:   outputDirectory,
+		DataVersion: schematicVersion,
+	}
+	var totalFunctions, totalCommand int
+	for i, set := range sets {
+		name := fmt.Sprintf("f%04d-i", i+1)
+		functions, err := exporter.Export(name, set)
+		if err != nil {
+			return err
+		}
+		totalFunctions += len(functions)
+		totalCommand += set.Len()
+
+		for _, f := range functions {
+			fmt.Printf("%s.mcfunction\n", f)
+		}
+
+		if exportSchematic {
+			files, err := schematic.Export(name, set)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				fmt.Printf("%s\n", f)
+			}
+		}
+	}
+	fmt.Printf("Total generated command/function: %d/%d\n", totalCommand, totalFunctions)
+	fmt.Printf("\nCreate function duration: %s\n", time.Since(createStart))
+	return nil
+}
+
+// Dimensions and block counts before writing
+func printSummary(sets []*m2m.VoxelSet) {
+	fmt.Printf("\nConversion result:\n")
+	var w, h, d, total int
+	totalUsedBlock := map[string]int{}
+	for _, set := range sets {
+		sw, sh, sd := set.Size()
+		w, h, d = max(w, sw), max(h, sh), max(d, sd)
+		total += set.Len()
+		for id, count := range set.UsedBlocks() {
+			totalUsedBlock[id] += count
+		}
+	}
+	fmt.Printf("Set:%d W:%d H:%d D:%d Block:%d Kind:%d\n", len(sets), w, h, d, total, len(totalUsedBlock))
+	if enableBlockCount {
+		printBlockCount(totalUsedBlock)
+	}
+}
+
+func printBlockCount(totalUsedBlock map[string]int) {
+	fmt.Printf("\nBlock information:\n")
+	type Count struct {
+		BlockID string `json:"blockID"`
+		Count   int    `json:"count"`
+	}
+	var blockCount []Count
+	for blockID, count := range totalUsedBlock {
+		blockCount = append(blockCount, Count{
+			BlockID: blockID,
+			Count:   count,
+		})
+	}
+
+	slices.SortFunc(blockCount, func(a, b Count) int {
+		return b.Count - a.Count // 降順にソート
+	})
+	for i, v := range blockCount {
+		fmt.Printf("% 4d: %-5s %d\n", i+1, v.BlockID, v.Count)
+	}
+}
@@ -1,51 +1,25 @@
-package main
+package colormatch
 
 import (
 	"math"
-)
-
-func getBlock(target Color) (blockID string) {
-	if colorDepthBit < 6 {
-		return colorMap[target.r>>uint8(8-colorDepthBit)][target.g>>uint8(8-colorDepthBit)][target.b>>uint8(8-colorDepthBit)]
-	}
-	return nearestColorBlock(target)
-}
-
-func nearestColorBlock(target Color) (blockID string) {
-	if id, ok := colorCache.Load(target); ok {
-		return id.(string)
-	}
 
-	var distance float64 = math.MaxFloat64
-	for _, block := range blockList {
-		// RGB
-		// d := RGBDistance(block.color, target)
-		// // HLS
-		// d := HSLDistance(blockColor[id], target)
-		// // Lab
-		d := LabDistance(block.color, target)
-
-		if d < distance {
-			blockID = block.id
-			distance = d
-		}
-	}
+	"github.com/aatomu/model2minecraft/palette"
+)
 
-	colorCache.Store(target, blockID)
-	return
-}
+// Color distance function
+type Metric func(a, b palette.Color) float64
 
-func RGBDistance(a, b Color) float64 {
-	tmp := float64(a.r) - float64(b.r)
+func RGBDistance(a, b palette.Color) float64 {
+	tmp := float64(a.R) - float64(b.R)
 	red := tmp * tmp
-	tmp = float64(a.g) - float64(b.g)
+	tmp = float64(a.G) - float64(b.G)
 	green := tmp * tmp
-	tmp = float64(a.b) - float64(b.b)
+	tmp = float64(a.B) - float64(b.B)
 	blue := tmp * tmp
 	return red + green + blue
 }
 
-func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+func RGBToHSL(r, g, b uint8) (h, s, l float64) {
 	// RGB(ff,ff,ff) => RGB(0..1,0..1,0..1)
 	fr := float64(r) / 255.0
 	fg := float64(g) / 255.0
@@ -88,9 +62,9 @@ func rgbToHSL(r, g, b uint8) (h, s, l float64) {
 	return h, s, l
 }
 
-func HSLDistance(a, b Color) float64 {
-	Ha, Sa, La := rgbToHSL(uint8(a.r), uint8(a.g), uint8(a.b))
-	Hb, Sb, Lb := rgbToHSL(uint8(b.r), uint8(b.g), uint8(b.b))
+func HSLDistance(a, b palette.Color) float64 {
+	Ha, Sa, La := RGBToHSL(a.R, a.G, a.B)
+	Hb, Sb, Lb := RGBToHSL(b.R, b.G, b.B)
 
 	// H distance
 	dh := math.Abs(Ha - Hb)
@@ -104,11 +78,11 @@ func HSLDistance(a, b Color) float64 {
 	return dh*dh + ds*ds + dl*dl
 }
 
-func rgbToLab(rgb Color) (float64, float64, float64) {
+func RGBToLab(rgb palette.Color) (float64, float64, float64) {
 	// RGB => XYZ
-	red := float64(rgb.r) / 255.0
-	green := float64(rgb.g) / 255.0
-	blue := float64(rgb.b) / 255.0
+	red := float64(rgb.R) / 255.0
+	green := float64(rgb.G) / 255.0
+	blue := float64(rgb.B) / 255.0
 
 	x := red*0.4124564 + green*0.3575761 + blue*0.1804375
 	y := red*0.2126729 + green*0.7151522 + blue*0.0721750
@@ -138,9 +112,37 @@ func rgbToLab(rgb Color) (float64, float64, float64) {
 	return L, A, B
 }
 
-func LabDistance(a, b Color) float64 {
-	La, Aa, Ba := rgbToLab(a)
-	Lb, Ab, Bb := rgbToLab(b)
+// Inverse of RGBToLab, out of gamut values are clamped
+func LabToRGB(L, A, B float64) palette.Color {
+	// Lab => XYZ
+	y := (L + 16) / 116
+	x := y + A/500
+	z := y - B/200
+
+	f := func(t float64) float64 {
+		if t3 := t * t * t; t3 > 0.008856 {
+			return t3
+		}
+		return (t - 16.0/116.0) / 7.787
+	}
+	x = f(x) * 0.95047
+	y = f(y) * 1.00000
+	z = f(z) * 1.08883
+
+	// XYZ => RGB
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	return palette.Color{
+		R: channel(x*3.2404542 - y*1.5371385 - z*0.4985314),
+		G: channel(-x*0.9692660 + y*1.8760108 + z*0.0415560),
+		B: channel(x*0.0556434 - y*0.2040259 + z*1.0572252),
+	}
+}
+
+func LabDistance(a, b palette.Color) float64 {
+	La, Aa, Ba := RGBToLab(a)
+	Lb, Ab, Bb := RGBToLab(b)
 
 	dL := (La - Lb) * (La - Lb)
 	dA := (Aa - Ab) * (Aa - Ab)
@@ -0,0 +1,102 @@
+// Package colormatch finds the nearest color block of the palette.
+package colormatch
+
+import (
+	"math"
+	"sync"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+type Matcher struct {
+	blocks   []palette.Block
+	metric   Metric
+	depthBit int
+	colorMap [][][]string // Color map use: depthBit < 6
+	cache    sync.Map     // map[palette.Color]string
+}
+
+// depthBit: 1-8, color map is precomputed when depthBit < 6
+//
+// metric: nil uses LabDistance
+func New(blocks []palette.Block, depthBit int, metric Metric) *Matcher {
+	if metric == nil {
+		metric = LabDistance
+	}
+	m := &Matcher{
+		blocks:   blocks,
+		metric:   metric,
+		depthBit: max(1, min(8, depthBit)),
+	}
+
+	if m.depthBit < 6 {
+		m.buildColorMap()
+	}
+	return m
+}
+
+func (m *Matcher) UseColorMap() bool {
+	return m.colorMap != nil
+}
+
+// block color to color mapping
+func (m *Matcher) buildColorMap() {
+	shift := 8 - m.depthBit
+	colorMaxValue := 0xff >> shift
+
+	m.colorMap = make([][][]string, colorMaxValue+1)
+	var wg sync.WaitGroup
+	for r := 0; r <= colorMaxValue; r++ {
+		m.colorMap[r] = make([][]string, colorMaxValue+1)
+		for g := 0; g <= colorMaxValue; g++ {
+			wg.Add(1)
+			go func(fR, fG int) {
+				defer wg.Done()
+				BColorMap := make([]string, colorMaxValue+1)
+				for b := 0; b <= colorMaxValue; b++ {
+					BColorMap[b] = m.Nearest(palette.Color{R: uint8(fR << shift), G: uint8(fG << shift), B: uint8(b << shift)})
+				}
+				m.colorMap[fR][fG] = BColorMap
+			}(r, g)
+		}
+	}
+	wg.Wait()
+}
+
+// Block ID for color, reduced by depth bit
+func (m *Matcher) Block(target palette.Color) (blockID string) {
+	if m.colorMap != nil {
+		shift := uint8(8 - m.depthBit)
+		return m.colorMap[target.R>>shift][target.G>>shift][target.B>>shift]
+	}
+	return m.Nearest(target)
+}
+
+// Nearest color block in full color
+func (m *Matcher) Nearest(target palette.Color) (blockID string) {
+	if id, ok := m.cache.Load(target); ok {
+		return id.(string)
+	}
+
+	blockID = m.NearestFunc(target, nil)
+
+	m.cache.Store(target, blockID)
+	return
+}
+
+// Nearest color block accepted by accept(nil: all blocks), not cached
+func (m *Matcher) NearestFunc(target palette.Color, accept func(block palette.Block) bool) (blockID string) {
+	var distance float64 = math.MaxFloat64
+	for _, block := range m.blocks {
+		if accept != nil && !accept(block) {
+			continue
+		}
+
+		d := m.metric(block.Color, target)
+		if d < distance {
+			blockID = block.ID
+			distance = d
+		}
+	}
+	return
+}
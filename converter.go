@@ -0,0 +1,230 @@
+// Package model2minecraft converts .obj, images and videos into Minecraft blocks.
+//
+// A Converter loads the block palette once, and each Convert method returns a
+// VoxelSet which can be written by an exporter from the export package.
+package model2minecraft
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aatomu/model2minecraft/colormatch"
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/mesh"
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/raster"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+type VoxelSet = voxel.Grid
+
+type Options struct {
+	// Minecraft Configuration
+	MinecraftDirectory string   // Assets extracted from version.jar
+	AllowedBlockIds    []string // Allowed regex patterns
+	IgnoredBlockIds    []string // Ignored regex patterns
+	BlockFilterPresets []string // Block meta presets: survival, renewable, non-flammable, no-tile-entities, no-light
+	ColorDepthBit      int      // 1-8
+	ColorMetric        colormatch.Metric
+	MergePolicy        voxel.MergePolicy // Overlapping samples in a voxel
+	Shading            voxel.Shading     // Directional light(mesh sources) and ambient occlusion before block matching
+	PhysicsMode        voxel.PhysicsMode // Handling of gravity/support/fluid blocks
+	SupportBlockId     string            // Block inserted by PhysicsSupport
+	BlockBudget        int               // Max voxels of a conversion, 0: unlimited
+	RemoveHidden       bool              // Delete voxels enclosed by 6 neighbors, keep the visible shell
+
+	// Object Configuration
+	ObjectDirectory          string // .mtl & texture directory
+	ObjectTriangulation      mesh.Triangulation
+	ObjectScale              float64
+	ObjectTransform          mesh.Transform // Rotate, mirror, fit and place after ObjectScale
+	ObjectGridSpacing        float64
+	ObjectConnectivity       mesh.Connectivity // Surface thickness
+	IsObjectUVYAxisUp        bool
+	ObjectTextureFilter      mesh.TextureFilter // Nearest, Bilinear, AreaAverage
+	ObjectTextureWrap        mesh.WrapMode      // Repeat, Clamp, Mirror
+	ObjectTintTexture        bool               // Multiply .obj map_Kd by Kd, glTF baseColorFactor is always applied
+	ObjectVertexColorTexture bool               // Multiply texture by "v x y z r g b" vertex colors
+	ObjectDefaultColor       palette.Color      // Untextured material without Kd/Ka
+	ObjectFillMode           voxel.FillMode     // Hollow, FloodFill, Parity
+	ObjectFillBlockId        string             // Interior block, "": nearest surface color
+	ObjectShellThickness     int                // Filled depth from surface, 0: fill all
+	STLBlockId               string             // Block of every .stl facet, "": facet color
+	VoxBlockIds              map[int]string     // .vox palette index(1-255) to block id overrides
+	PointMinimumCount        int                // .ply/.xyz points needed for a voxel, fewer is noise
+	ModelScale               float64            // Minecraft model: blocks per model pixel(1/16 block)
+	ParallelLimit            int
+
+	// Image/Video Configuration
+	ImageResize    raster.Resize    // Crop and resample images(Image, MapArt)
+	ImagePlacement raster.Placement // Wall/floor/ceiling, rotation and flips of pixels
+	MapArtMode     MapArtMode       // Map art heights: MapFlat, MapStaircase, MapValley
+
+	// Video Configuration (*requires ffmpeg)
+	VideoFrameRate int
+	VideoScaleSize string // ffmpeg rescale argument
+
+	// Progress output (nil: discard)
+	Log io.Writer
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MinecraftDirectory: "./assets",
+		AllowedBlockIds:    []string{""},
+		IgnoredBlockIds:    []string{"^powder_snow$", "glass", "spawner", "ice"},
+		ColorDepthBit:      8,
+		MergePolicy:        voxel.FirstWins,
+		PhysicsMode:        voxel.PhysicsSupport,
+		SupportBlockId:     "stone",
+		ObjectDirectory:    ".",
+		ObjectScale:        1.0,
+		ObjectGridSpacing:  1.0,
+		IsObjectUVYAxisUp:  true,
+		ObjectDefaultColor: palette.Color{R: 200, G: 200, B: 200},
+		PointMinimumCount:  1,
+		ModelScale:         1.0,
+		ParallelLimit:      10,
+		VideoFrameRate:     20,
+		VideoScaleSize:     "200:-1",
+	}
+}
+
+type Converter struct {
+	opt     Options
+	log     io.Writer
+	blocks  []palette.Block
+	matcher *colormatch.Matcher
+	report  *diagnostic.Report
+}
+
+// Load block palette and build color mapping
+func New(opt Options) (*Converter, error) {
+	if opt.ParallelLimit <= 0 {
+		opt.ParallelLimit = 1
+	}
+	if opt.ObjectGridSpacing <= 0 {
+		return nil, fmt.Errorf("object grid spacing must be positive: %f", opt.ObjectGridSpacing)
+	}
+
+	c := &Converter{
+		opt: opt,
+		log: opt.Log,
+	}
+	if c.log == nil {
+		c.log = io.Discard
+	}
+	c.report = diagnostic.NewReport(c.log)
+
+	// minecraft block
+	blockStart := time.Now()
+	fmt.Fprintf(c.log, "\nBlock parse start...\n")
+	blocks, err := palette.Load(palette.Options{
+		Directory: opt.MinecraftDirectory,
+		Allowed:   opt.AllowedBlockIds,
+		Ignored:   opt.IgnoredBlockIds,
+		Presets:   opt.BlockFilterPresets,
+	}, c.report)
+	if err != nil {
+		return nil, err
+	}
+	c.blocks = blocks
+	fmt.Fprintf(c.log, "All sides are same& name filtered block: %d\n", len(blocks))
+	fmt.Fprintf(c.log, "\nBlock parse duration: %s\n", time.Since(blockStart))
+
+	// block color to color mapping
+	colorStart := time.Now()
+	c.matcher = colormatch.New(blocks, opt.ColorDepthBit, opt.ColorMetric)
+	if c.matcher.UseColorMap() {
+		fmt.Fprintf(c.log, "\n%dBit color mapping duration: %s\n", opt.ColorDepthBit, time.Since(colorStart))
+	} else {
+		fmt.Fprintf(c.log, "\n\nDon't use color map.\n\n")
+	}
+
+	return c, nil
+}
+
+// Filtered palette
+func (c *Converter) Blocks() []palette.Block {
+	return c.blocks
+}
+
+// Block ID for color
+func (c *Converter) Block(color palette.Color) string {
+	return c.matcher.Block(color)
+}
+
+// Skipped errors of all conversions
+func (c *Converter) Diagnostics() *diagnostic.Report {
+	return c.report
+}
+
+// Majority vote counts matched blocks instead of colors
+func (c *Converter) newGrid(spacing float64) *VoxelSet {
+	grid := voxel.NewGrid(spacing, c.opt.MergePolicy)
+	if c.opt.MergePolicy == voxel.MajorityVote {
+		grid.Vote = c.matcher.Block
+	}
+	return grid
+}
+
+// Resolve merged samples, apply LOD, shade, assign block ids and apply physics
+func (c *Converter) finalize(grid *VoxelSet) {
+	grid.Resolve()
+	c.removeHidden(grid)
+	c.decimate(grid)
+	grid.Occlude(c.opt.Shading)
+	grid.AssignBlocks(c.matcher.Block)
+	grid.ApplyPhysics(c.opt.PhysicsMode, c.opt.SupportBlockId, c.nearestSolidBlock)
+}
+
+func (c *Converter) removeHidden(grid *VoxelSet) {
+	if !c.opt.RemoveHidden {
+		return
+	}
+	if removed := grid.RemoveHidden(); removed > 0 {
+		fmt.Fprintf(c.log, "Remove hidden: %d voxels\n", removed)
+	}
+}
+
+// Shrink grid by an integer factor fitting BlockBudget
+func (c *Converter) decimate(grid *VoxelSet) {
+	budget := c.opt.BlockBudget
+	if budget <= 0 || grid.Len() <= budget {
+		return
+	}
+	before := grid.Len()
+	w, h, d := grid.Size()
+	// Surfaces shrink with the square of factor, sparse voxels slower
+	start := max(2, int(math.Sqrt(float64(before)/float64(budget))))
+	for factor := start; ; factor = max(factor+1, factor*5/4) {
+		small := grid.Downsample(factor)
+		if c.opt.RemoveHidden {
+			small.RemoveHidden()
+		}
+		if small.Len() <= budget || factor >= max(w, h, d) {
+			fmt.Fprintf(c.log, "Decimate 1/%d: %d -> %d voxels(budget: %d)\n", factor, before, small.Len(), budget)
+			if small.Len() > budget {
+				c.report.Add("lod", "", 0, fmt.Errorf("%d voxels are over block budget %d", small.Len(), budget))
+			}
+			*grid = *small
+			return
+		}
+	}
+}
+
+func (c *Converter) nearestSolidBlock(color palette.Color) string {
+	return c.matcher.NearestFunc(color, func(block palette.Block) bool {
+		return palette.PhysicsOf(block.ID) == palette.Solid
+	})
+}
+
+// File name of reader if exists
+func sourceName(r io.Reader, fallback string) string {
+	if f, ok := r.(interface{ Name() string }); ok {
+		return f.Name()
+	}
+	return fallback
+}
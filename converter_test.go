@@ -0,0 +1,35 @@
+package model2minecraft
+
+import (
+	"io"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/colormatch"
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+var testBlocks = []palette.Block{
+	{ID: "white_wool", Color: palette.Color{R: 255, G: 255, B: 255}},
+	{ID: "black_wool", Color: palette.Color{R: 0, G: 0, B: 0}},
+	{ID: "red_wool", Color: palette.Color{R: 255, G: 0, B: 0}},
+	{ID: "blue_wool", Color: palette.Color{R: 0, G: 0, B: 255}},
+}
+
+// Converter with testBlocks instead of a Minecraft assets directory
+func newTestConverter(t *testing.T, configure func(*Options)) *Converter {
+	t.Helper()
+	opt := DefaultOptions()
+	opt.PhysicsMode = voxel.PhysicsIgnore
+	if configure != nil {
+		configure(&opt)
+	}
+	return &Converter{
+		opt:     opt,
+		log:     io.Discard,
+		blocks:  testBlocks,
+		matcher: colormatch.New(testBlocks, opt.ColorDepthBit, opt.ColorMetric),
+		report:  diagnostic.NewReport(nil),
+	}
+}
@@ -0,0 +1,80 @@
+// Package diagnostic collects non-fatal errors found while converting.
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Non-fatal error found while converting
+type Diagnostic struct {
+	Stage string // "block", "mtl", "object", "image", "video", "function"
+	File  string
+	Line  int // 1 origin, 0: whole file
+	Err   error
+}
+
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("[%s] %s:%d: %s", d.Stage, d.File, d.Line, d.Err)
+	}
+	return fmt.Sprintf("[%s] %s: %s", d.Stage, d.File, d.Err)
+}
+
+// Concurrent safe diagnostics list
+type Report struct {
+	mu    sync.Mutex
+	items []Diagnostic
+	log   io.Writer
+}
+
+// log: each diagnostic is also written when added (nil: discard)
+func NewReport(log io.Writer) *Report {
+	if log == nil {
+		log = io.Discard
+	}
+	return &Report{log: log}
+}
+
+// nil Report ignores diagnostics
+func (r *Report) Add(stage, file string, line int, err error) {
+	if r == nil {
+		return
+	}
+	d := Diagnostic{Stage: stage, File: file, Line: line, Err: err}
+	fmt.Fprintf(r.log, "Error %s\n", d)
+
+	r.mu.Lock()
+	r.items = append(r.items, d)
+	r.mu.Unlock()
+}
+
+func (r *Report) Items() []Diagnostic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Diagnostic(nil), r.items...)
+}
+
+func (r *Report) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.items)
+}
+
+func (r *Report) Print(w io.Writer) {
+	items := r.Items()
+	if len(items) == 0 {
+		fmt.Fprintf(w, "\nDiagnostics: no errors\n")
+		return
+	}
+
+	count := map[string]int{}
+	for _, d := range items {
+		count[d.Stage]++
+	}
+	fmt.Fprintf(w, "\nDiagnostics: %d errors %v\n", len(items), count)
+	for _, d := range items {
+		fmt.Fprintf(w, "  %s\n", d)
+	}
+}
@@ -0,0 +1,13 @@
+// Package export writes voxel sets as Minecraft files.
+package export
+
+import (
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// name: output base name
+//
+// files: written file names
+type Exporter interface {
+	Export(name string, grid *voxel.Grid) (files []string, err error)
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Generate one command from a voxel
+type Command func(v voxel.Voxel) (cmd string)
+
+// setblock with relative position
+func SetblockCommand(v voxel.Voxel) string {
+	return fmt.Sprintf("setblock ~%.2f ~%.2f ~%.2f %s", v.Position.X, v.Position.Y, v.Position.Z, v.BlockID)
+}
+
+// Split voxels into .mcfunction files
+type MCFunction struct {
+	Directory       string
+	MaxCommandChain int     // Commands per file
+	Generator       Command // nil: SetblockCommand
+}
+
+// Files are named "{name}{0001..}.mcfunction"
+func (e MCFunction) Export(name string, grid *voxel.Grid) (funcs []string, err error) {
+	generator := e.Generator
+	if generator == nil {
+		generator = SetblockCommand
+	}
+	chain := e.MaxCommandChain
+	if chain <= 0 {
+		chain = 65536
+	}
+
+	voxels := grid.Voxels()
+	funcs = []string{}
+	for i := 0; i*chain < len(voxels); i++ {
+		var builder strings.Builder
+		start := i * chain
+		end := min((i+1)*chain, len(voxels))
+		for _, v := range voxels[start:end] {
+			builder.WriteString(generator(v))
+			builder.WriteString("\n")
+		}
+		file := fmt.Sprintf("%s%04d", name, i+1)
+		err = os.WriteFile(filepath.Join(e.Directory, file+".mcfunction"), []byte(builder.String()), 0777)
+		if err != nil {
+			return funcs, err
+		}
+		funcs = append(funcs, file)
+	}
+
+	return
+}
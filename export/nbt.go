@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// NBT tag types
+const (
+	tagEnd       byte = 0
+	tagShort     byte = 2
+	tagInt       byte = 3
+	tagByteArray byte = 7
+	tagList      byte = 9
+	tagCompound  byte = 10
+	tagIntArray  byte = 11
+)
+
+// Big endian NBT writer, the first error is kept
+type nbtWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (n *nbtWriter) write(v any) {
+	if n.err == nil {
+		n.err = binary.Write(n.w, binary.BigEndian, v)
+	}
+}
+
+func (n *nbtWriter) header(tag byte, name string) {
+	n.write(tag)
+	n.write(uint16(len(name)))
+	n.write([]byte(name))
+}
+
+func (n *nbtWriter) beginCompound(name string) {
+	n.header(tagCompound, name)
+}
+
+func (n *nbtWriter) endCompound() {
+	n.write(tagEnd)
+}
+
+func (n *nbtWriter) short(name string, v int16) {
+	n.header(tagShort, name)
+	n.write(v)
+}
+
+func (n *nbtWriter) int(name string, v int32) {
+	n.header(tagInt, name)
+	n.write(v)
+}
+
+func (n *nbtWriter) byteArray(name string, v []byte) {
+	n.header(tagByteArray, name)
+	n.write(int32(len(v)))
+	n.write(v)
+}
+
+func (n *nbtWriter) intArray(name string, v []int32) {
+	n.header(tagIntArray, name)
+	n.write(int32(len(v)))
+	n.write(v)
+}
+
+// List without elements
+func (n *nbtWriter) emptyList(name string, element byte) {
+	n.header(tagList, name)
+	n.write(element)
+	n.write(int32(0))
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Minecraft 1.21
+const defaultDataVersion = 3953
+
+// Sponge schematic v2(.schem) for WorldEdit and similar tools
+//
+// Voxels are placed at their rounded world position, Offset keeps the position relative to the paste point.
+type Schematic struct {
+	Directory   string
+	DataVersion int // Minecraft data version, 0: 1.21
+}
+
+// File is named "{name}.schem"
+func (e Schematic) Export(name string, grid *voxel.Grid) (files []string, err error) {
+	dataVersion := e.DataVersion
+	if dataVersion <= 0 {
+		dataVersion = defaultDataVersion
+	}
+
+	voxels := grid.Voxels()
+	positions := make([][3]int, len(voxels))
+	lo := [3]int{math.MaxInt, math.MaxInt, math.MaxInt}
+	hi := [3]int{math.MinInt, math.MinInt, math.MinInt}
+	for i, v := range voxels {
+		p := [3]int{int(math.Round(v.Position.X)), int(math.Round(v.Position.Y)), int(math.Round(v.Position.Z))}
+		positions[i] = p
+		for axis := 0; axis < 3; axis++ {
+			lo[axis] = min(lo[axis], p[axis])
+			hi[axis] = max(hi[axis], p[axis])
+		}
+	}
+	if len(voxels) == 0 {
+		lo, hi = [3]int{}, [3]int{-1, -1, -1}
+	}
+	width, height, length := hi[0]-lo[0]+1, hi[1]-lo[1]+1, hi[2]-lo[2]+1
+	if width > math.MaxUint16 || height > math.MaxUint16 || length > math.MaxUint16 {
+		return nil, fmt.Errorf("%s: schematic size %dx%dx%d is over 65535", name, width, height, length)
+	}
+
+	// Palette index 0 is air
+	palette := map[string]int{"minecraft:air": 0}
+	ids := []string{"minecraft:air"}
+	cells := make([]int, width*height*length)
+	for i, v := range voxels {
+		id := v.BlockID
+		if id == "" {
+			continue
+		}
+		if !strings.Contains(id, ":") {
+			id = "minecraft:" + id
+		}
+		index, ok := palette[id]
+		if !ok {
+			index = len(palette)
+			palette[id] = index
+			ids = append(ids, id)
+		}
+		p := positions[i]
+		x, y, z := p[0]-lo[0], p[1]-lo[1], p[2]-lo[2]
+		cells[x+z*width+y*width*length] = index
+	}
+	var blockData []byte
+	for _, index := range cells {
+		blockData = binary.AppendUvarint(blockData, uint64(index))
+	}
+
+	file := name + ".schem"
+	f, err := os.Create(filepath.Join(e.Directory, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buffer := bufio.NewWriter(f)
+	zw := gzip.NewWriter(buffer)
+
+	n := &nbtWriter{w: zw}
+	n.beginCompound("Schematic")
+	n.int("Version", 2)
+	n.int("DataVersion", int32(dataVersion))
+	n.short("Width", int16(uint16(width)))
+	n.short("Height", int16(uint16(height)))
+	n.short("Length", int16(uint16(length)))
+	n.intArray("Offset", []int32{int32(lo[0]), int32(lo[1]), int32(lo[2])})
+	n.int("PaletteMax", int32(len(palette)))
+	n.beginCompound("Palette")
+	for index, id := range ids {
+		n.int(id, int32(index))
+	}
+	n.endCompound()
+	n.byteArray("BlockData", blockData)
+	n.emptyList("BlockEntities", tagCompound)
+	n.endCompound()
+
+	if n.err != nil {
+		return nil, n.err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if err := buffer.Flush(); err != nil {
+		return nil, err
+	}
+	return []string{file}, nil
+}
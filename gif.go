@@ -0,0 +1,117 @@
+package model2minecraft
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aatomu/model2minecraft/raster"
+)
+
+// Delay of frames with 0 or 1 delay, same as web browsers
+const defaultGIFDelay = 10 // 1/100 seconds
+
+// Convert animated .gif frames at VideoFrameRate, each GIF frame is shown for its delay
+//
+// Frames are composited over the background color by their disposal methods,
+// so every frame set covers the whole image like video frames.
+func (c *Converter) ConvertGIF(r io.Reader) ([]*VoxelSet, error) {
+	gifStart := time.Now()
+	fmt.Fprintf(c.log, "\nGIF parse start...\n")
+	if c.opt.VideoFrameRate <= 0 {
+		return nil, fmt.Errorf("video frame rate must be positive: %d", c.opt.VideoFrameRate)
+	}
+
+	name := sourceName(r, "animation.gif")
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode gif: %w", name, err)
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("%s: no frame found", name)
+	}
+
+	// Composite frames
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	background := image.NewUniform(color.RGBA{0, 0, 0, 255})
+	if p, ok := g.Config.ColorModel.(color.Palette); ok && int(g.BackgroundIndex) < len(p) {
+		cr, cg, cb, _ := p[g.BackgroundIndex].RGBA()
+		background = image.NewUniform(color.RGBA{uint8(cr >> 8), uint8(cg >> 8), uint8(cb >> 8), 255})
+	}
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, background, image.Point{}, draw.Src)
+
+	var (
+		images = make([]image.Image, len(g.Image))
+		ends   = make([]float64, len(g.Image)) // Seconds when each frame ends
+		total  float64
+	)
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, image.Point{}, draw.Src)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if images[i], err = c.opt.ImageResize.Apply(canvas); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		delay := defaultGIFDelay
+		if i < len(g.Delay) && g.Delay[i] > 1 {
+			delay = g.Delay[i]
+		}
+		total += float64(delay) / 100
+		ends[i] = total
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), background, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		session = make(chan struct{}, c.opt.ParallelLimit)
+		frames  = make([]*VoxelSet, len(images))
+	)
+	for i, img := range images {
+		session <- struct{}{}
+		wg.Add(1)
+		go func(fFrame int, fImage image.Image) {
+			defer func() {
+				<-session
+				wg.Done()
+			}()
+
+			frames[fFrame] = c.pixelsToVoxels(raster.Pixels(fImage))
+			fmt.Fprintf(c.log, "Finish: frame: %d(%5.2f/%5.2f)\n", fFrame+1, ends[fFrame], total)
+		}(i, img)
+	}
+	wg.Wait()
+
+	// GIF frames on the VideoFrameRate timeline, long frames are repeated and short frames may be skipped
+	var sets []*VoxelSet
+	frame := 0
+	for current := 0.0; current < total; current += 1.0 / float64(c.opt.VideoFrameRate) {
+		for frame < len(ends)-1 && ends[frame] <= current {
+			frame++
+		}
+		sets = append(sets, frames[frame])
+	}
+
+	fmt.Fprintf(c.log, "\nGIF parse duration: %s, GIF frame: %d, Frame: %d(%.2fs)\n", time.Since(gifStart), len(frames), len(sets), total)
+	return sets, nil
+}
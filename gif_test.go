@@ -0,0 +1,64 @@
+package model2minecraft
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"maps"
+	"testing"
+)
+
+func TestConvertGIF(t *testing.T) {
+	p := color.Palette{color.Black, color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+
+	// Frame 1: all red, cleared to the background
+	// Frame 2: blue top left pixel, kept under frame 3
+	// Frame 3: no change, restored to frame 2 after it
+	red := image.NewPaletted(image.Rect(0, 0, 2, 2), p)
+	for i := range red.Pix {
+		red.Pix[i] = 1
+	}
+	blue := image.NewPaletted(image.Rect(0, 0, 1, 1), p)
+	blue.Pix[0] = 2
+	same := image.NewPaletted(image.Rect(1, 1, 2, 2), p)
+	same.Pix[0] = 1
+
+	var b bytes.Buffer
+	err := gif.EncodeAll(&b, &gif.GIF{
+		Image:    []*image.Paletted{red, blue, same},
+		Delay:    []int{10, 20, 0},
+		Disposal: []byte{gif.DisposalBackground, gif.DisposalNone, gif.DisposalPrevious},
+		Config:   image.Config{ColorModel: p, Width: 2, Height: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestConverter(t, func(opt *Options) {
+		opt.VideoFrameRate = 10
+	})
+	sets, err := c.ConvertGIF(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.1s + 0.2s + 0.1s(delay 0) at 10 fps
+	if len(sets) != 4 {
+		t.Fatalf("%d frames, want 4", len(sets))
+	}
+	want := []map[string]int{
+		{"red_wool": 4},
+		{"blue_wool": 1, "black_wool": 3},
+		{"blue_wool": 1, "black_wool": 3},
+		{"blue_wool": 1, "black_wool": 2, "red_wool": 1},
+	}
+	for i, set := range sets {
+		if used := set.UsedBlocks(); !maps.Equal(used, want[i]) {
+			t.Errorf("frame %d: %v, want %v", i, used, want[i])
+		}
+	}
+	if sets[1] != sets[2] {
+		t.Error("long GIF frame is not repeated")
+	}
+}
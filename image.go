@@ -1,33 +1,47 @@
-package main
+package model2minecraft
 
 import (
-	"image"
+	"fmt"
 	"io"
+	"time"
+
+	"github.com/aatomu/model2minecraft/raster"
 )
 
-type pixel struct {
-	color Color
-	x, y  float64
-}
+// Convert .png/.jpeg to a plane placed by ImagePlacement
+func (c *Converter) ConvertImage(r io.Reader) (*VoxelSet, error) {
+	imageStart := time.Now()
+	fmt.Fprintf(c.log, "\nImage parse start...\n")
+
+	pixels, err := c.imagePixels(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sourceName(r, "image"), err)
+	}
 
-func parseImage(f io.Reader) (p []pixel) {
-	img, _, _ := image.Decode(f)
-	bounds := img.Bounds()
+	grid := c.pixelsToVoxels(pixels)
+	w, h, _ := grid.Size()
+	fmt.Fprintf(c.log, "\nImage parse duration: %s W:%d H:%d\n", time.Since(imageStart), w, h)
+	return grid, nil
+}
 
-	for x := bounds.Min.X; x < bounds.Max.X; x++ {
-		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-			r, g, b, _ := img.At(x, y).RGBA()
-			p = append(p, pixel{
-				color: Color{
-					r: uint8(r >> 8),
-					g: uint8(g >> 8),
-					b: uint8(b >> 8),
-				},
-				x: float64(x),
-				y: float64(bounds.Max.Y - y),
-			})
-		}
+// Decode image, crop and resize by ImageResize
+func (c *Converter) imagePixels(r io.Reader) ([]raster.Pixel, error) {
+	img, err := raster.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	img, err = c.opt.ImageResize.Apply(img)
+	if err != nil {
+		return nil, err
 	}
+	return raster.Pixels(img), nil
+}
 
-	return
+func (c *Converter) pixelsToVoxels(pixels []raster.Pixel) *VoxelSet {
+	grid := c.newGrid(1.0)
+	for _, s := range c.opt.ImagePlacement.Place(pixels) {
+		grid.WriteAt(s.Position, s.Color, "")
+	}
+	c.finalize(grid)
+	return grid
 }
@@ -0,0 +1,181 @@
+package model2minecraft
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aatomu/model2minecraft/colormatch"
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Map art height mode
+type MapArtMode int
+
+const (
+	MapFlat      MapArtMode = iota // 1 shade, all blocks at one height
+	MapStaircase                   // 3 shades, heights accumulate along each column
+	MapValley                      // 3 shades, lowest heights giving the same shades
+)
+
+// Blocks per map tile side
+const mapTileSize = 128
+
+type mapTone struct {
+	color palette.MapColor
+	shade palette.MapShade
+}
+
+// Convert .png/.jpeg to map art on the XZ plane, one set per 128×128 map tile
+//
+// Image top is north(-Z). Each tile has a reference row of SupportBlockId at z = -1,
+// the shade of the first pixel row is set by its height.
+func (c *Converter) ConvertMapArt(r io.Reader) ([]*VoxelSet, error) {
+	mapStart := time.Now()
+	fmt.Fprintf(c.log, "\nMap art parse start...\n")
+
+	name := sourceName(r, "image")
+	pixels, err := c.imagePixels(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	blocks, err := palette.MapArtBlocks(palette.Options{
+		Allowed: c.opt.AllowedBlockIds,
+		Ignored: c.opt.IgnoredBlockIds,
+		Presets: c.opt.BlockFilterPresets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reachable map tones, block id of the matcher is the tones index
+	shades := []palette.MapShade{palette.ShadeFlat}
+	if c.opt.MapArtMode != MapFlat {
+		shades = []palette.MapShade{palette.ShadeLow, palette.ShadeFlat, palette.ShadeHigh}
+	}
+	var (
+		tones      []mapTone
+		candidates []palette.Block
+	)
+	colors := make([]palette.MapColor, 0, len(blocks))
+	for color := range blocks {
+		colors = append(colors, color)
+	}
+	slices.Sort(colors)
+	for _, color := range colors {
+		for _, shade := range shades {
+			candidates = append(candidates, palette.Block{ID: strconv.Itoa(len(tones)), Color: color.Shade(shade)})
+			tones = append(tones, mapTone{color: color, shade: shade})
+		}
+	}
+	matcher := colormatch.New(candidates, c.opt.ColorDepthBit, c.opt.ColorMetric)
+
+	// [row][column] tone index, -1: no pixel
+	var width, height int
+	for _, pixel := range pixels {
+		width = max(width, int(pixel.X)+1)
+		height = max(height, int(pixel.Y))
+	}
+	image := make([][]int, height)
+	for row := range image {
+		image[row] = slices.Repeat([]int{-1}, width)
+	}
+	for _, pixel := range pixels {
+		index, _ := strconv.Atoi(matcher.Block(pixel.Color))
+		image[height-int(pixel.Y)][int(pixel.X)] = index
+	}
+
+	var sets []*VoxelSet
+	for tileZ := 0; tileZ*mapTileSize < height; tileZ++ {
+		for tileX := 0; tileX*mapTileSize < width; tileX++ {
+			grid := c.newGrid(1.0)
+			for x := 0; x < mapTileSize && tileX*mapTileSize+x < width; x++ {
+				var column []int
+				for z := 0; z < mapTileSize && tileZ*mapTileSize+z < height; z++ {
+					column = append(column, image[tileZ*mapTileSize+z][tileX*mapTileSize+x])
+				}
+				c.placeMapColumn(grid, x, column, tones, blocks)
+			}
+			grid.ApplyPhysics(c.opt.PhysicsMode, c.opt.SupportBlockId, c.nearestSolidBlock)
+
+			_, h, _ := grid.Size()
+			fmt.Fprintf(c.log, "Map tile [%d,%d]: Block:%d Height:%d\n", tileX, tileZ, grid.Len(), h)
+			sets = append(sets, grid)
+		}
+	}
+
+	fmt.Fprintf(c.log, "\nMap art parse duration: %s W:%d H:%d Tile:%d\n", time.Since(mapStart), width, height, len(sets))
+	return sets, nil
+}
+
+// Place column pixels from north to south at x, after the reference block at z = -1
+func (c *Converter) placeMapColumn(grid *VoxelSet, x int, column []int, tones []mapTone, blocks map[palette.MapColor]string) {
+	// Height change from the block north of each pixel
+	steps := make([]int, len(column))
+	for i, index := range column {
+		if index < 0 {
+			continue
+		}
+		switch tones[index].shade {
+		case palette.ShadeLow:
+			steps[i] = -1
+		case palette.ShadeHigh:
+			steps[i] = 1
+		}
+	}
+	heights := mapHeights(steps, c.opt.MapArtMode)
+
+	grid.Put(voxel.Pos{X: x, Y: heights[0], Z: -1}, palette.Color{}, c.opt.SupportBlockId)
+	for z, index := range column {
+		if index < 0 {
+			continue
+		}
+		tone := tones[index]
+		grid.Put(voxel.Pos{X: x, Y: heights[z+1], Z: z}, tone.color.Shade(tone.shade), blocks[tone.color])
+	}
+}
+
+// Heights of the reference block and each pixel, the lowest is 0
+//
+// steps: -1 lower, 0 same, +1 higher than the previous block
+func mapHeights(steps []int, mode MapArtMode) []int {
+	heights := make([]int, len(steps)+1)
+	switch mode {
+	case MapStaircase:
+		for i, step := range steps {
+			heights[i+1] = heights[i] + step
+		}
+	case MapValley:
+		// Rising runs count from the north, falling runs count from the south
+		rise := make([]int, len(heights))
+		fall := make([]int, len(heights))
+		for i := 1; i < len(heights); i++ {
+			switch steps[i-1] {
+			case 1:
+				rise[i] = rise[i-1] + 1
+			case 0:
+				rise[i] = rise[i-1]
+			}
+		}
+		for i := len(heights) - 2; i >= 0; i-- {
+			switch steps[i] {
+			case -1:
+				fall[i] = fall[i+1] + 1
+			case 0:
+				fall[i] = fall[i+1]
+			}
+		}
+		for i := range heights {
+			heights[i] = max(rise[i], fall[i])
+		}
+	}
+
+	lowest := slices.Min(heights)
+	for i := range heights {
+		heights[i] -= lowest
+	}
+	return heights
+}
@@ -0,0 +1,117 @@
+package model2minecraft
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+func TestMapHeights(t *testing.T) {
+	steps := []int{1, 1, -1, 0}
+	if h := mapHeights(steps, MapStaircase); !slices.Equal(h, []int{0, 1, 2, 1, 1}) {
+		t.Errorf("staircase %v", h)
+	}
+	if h := mapHeights(steps, MapValley); !slices.Equal(h, []int{0, 1, 2, 0, 0}) {
+		t.Errorf("valley %v", h)
+	}
+	if h := mapHeights(steps, MapFlat); !slices.Equal(h, []int{0, 0, 0, 0, 0}) {
+		t.Errorf("flat %v", h)
+	}
+
+	// Every mode keeps the step signs, valley is never higher than staircase
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		steps := make([]int, r.Intn(40))
+		for i := range steps {
+			steps[i] = r.Intn(3) - 1
+		}
+		staircase, valley := mapHeights(steps, MapStaircase), mapHeights(steps, MapValley)
+		for _, heights := range [][]int{staircase, valley} {
+			if slices.Min(heights) != 0 {
+				t.Fatalf("steps %v: heights %v, lowest is not 0", steps, heights)
+			}
+			for i, step := range steps {
+				if d := heights[i+1] - heights[i]; (d > 0) != (step > 0) || (d < 0) != (step < 0) {
+					t.Fatalf("steps %v: heights %v, step %d is %d", steps, heights, i, d)
+				}
+			}
+		}
+		if slices.Max(valley) > slices.Max(staircase) {
+			t.Fatalf("steps %v: valley %v is higher than staircase %v", steps, valley, staircase)
+		}
+	}
+}
+
+func encodePNG(t *testing.T, img image.Image) *bytes.Buffer {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	return &b
+}
+
+func mapColor(m palette.MapColor, shade palette.MapShade) color.NRGBA {
+	c := m.Shade(shade)
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}
+}
+
+func TestConvertMapArtTiles(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 130, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 130; x++ {
+			img.SetNRGBA(x, y, mapColor(8, palette.ShadeFlat))
+		}
+	}
+	c := newTestConverter(t, func(opt *Options) {
+		opt.MapArtMode = MapFlat
+	})
+	sets, err := c.ConvertMapArt(encodePNG(t, img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("%d tiles, want 2", len(sets))
+	}
+	// Pixels and the reference row
+	if n := sets[0].Len(); n != 128*2+128 {
+		t.Errorf("tile 0: %d blocks, want %d", n, 128*2+128)
+	}
+	if n := sets[1].Len(); n != 2*2+2 {
+		t.Errorf("tile 1: %d blocks, want %d", n, 2*2+2)
+	}
+	if v, _ := sets[0].Get(voxel.Pos{X: 0, Y: 0, Z: -1}); v.BlockID != c.opt.SupportBlockId {
+		t.Errorf("reference block %q, want %q", v.BlockID, c.opt.SupportBlockId)
+	}
+	if v, _ := sets[1].Get(voxel.Pos{X: 1, Y: 0, Z: 1}); v.BlockID != "snow_block" {
+		t.Errorf("pixel block %q, want snow_block", v.BlockID)
+	}
+}
+
+func TestConvertMapArtStaircase(t *testing.T) {
+	// North to south: higher, higher, lower
+	shades := []palette.MapShade{palette.ShadeHigh, palette.ShadeHigh, palette.ShadeLow}
+	img := image.NewNRGBA(image.Rect(0, 0, 1, len(shades)))
+	for y, shade := range shades {
+		img.SetNRGBA(0, y, mapColor(8, shade))
+	}
+	c := newTestConverter(t, func(opt *Options) {
+		opt.MapArtMode = MapStaircase
+	})
+	sets, err := c.ConvertMapArt(encodePNG(t, img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for z, y := range []int{0, 1, 2, 1} {
+		if !sets[0].Has(voxel.Pos{X: 0, Y: y, Z: z - 1}) {
+			t.Errorf("no block at z %d y %d", z-1, y)
+		}
+	}
+}
@@ -0,0 +1,645 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+)
+
+type gltfDocument struct {
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Children    []int     `json:"children"`
+		Mesh        *int      `json:"mesh"`
+		Matrix      []float64 `json:"matrix"`
+		Translation []float64 `json:"translation"`
+		Rotation    []float64 `json:"rotation"` // x, y, z, w
+		Scale       []float64 `json:"scale"`
+		Name        string    `json:"name"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Accessors []struct {
+		BufferView    *int   `json:"bufferView"`
+		ByteOffset    int    `json:"byteOffset"`
+		ComponentType int    `json:"componentType"`
+		Normalized    bool   `json:"normalized"`
+		Count         int    `json:"count"`
+		Type          string `json:"type"`
+		Sparse        any    `json:"sparse"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+	Materials []struct {
+		Name                 string `json:"name"`
+		PbrMetallicRoughness *struct {
+			BaseColorFactor  []float64 `json:"baseColorFactor"`
+			BaseColorTexture *struct {
+				Index    int `json:"index"`
+				TexCoord int `json:"texCoord"`
+			} `json:"baseColorTexture"`
+		} `json:"pbrMetallicRoughness"`
+	} `json:"materials"`
+	Textures []struct {
+		Source *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		URI        string `json:"uri"`
+		BufferView *int   `json:"bufferView"`
+		MimeType   string `json:"mimeType"`
+	} `json:"images"`
+}
+
+type gltfParser struct {
+	doc       gltfDocument
+	name      string
+	directory string
+	buffers   [][]byte
+	report    *diagnostic.Report
+	mesh      *Mesh
+	textures  map[int]Texture
+}
+
+// Parse .gltf(JSON with external/data uri buffers) or .glb
+//
+// directory: external buffer & image directory
+func ParseGLTF(r io.Reader, name, directory string, report *diagnostic.Report) (*Mesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &gltfParser{
+		name:      name,
+		directory: directory,
+		report:    report,
+		textures:  map[int]Texture{},
+		mesh: &Mesh{
+			Name:      name,
+			Materials: map[string]*Material{},
+			UVTopLeft: true,
+		},
+	}
+
+	jsonChunk := data
+	var binChunk []byte
+	if bytes.HasPrefix(data, []byte("glTF")) {
+		jsonChunk, binChunk, err = splitGLB(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := json.Unmarshal(jsonChunk, &p.doc); err != nil {
+		return nil, fmt.Errorf("%s: parse json: %w", name, err)
+	}
+
+	if err := p.loadBuffers(binChunk); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	p.loadMaterials()
+
+	// Walk scene nodes
+	roots := []int{}
+	switch {
+	case p.doc.Scene != nil && *p.doc.Scene < len(p.doc.Scenes):
+		roots = p.doc.Scenes[*p.doc.Scene].Nodes
+	case len(p.doc.Scenes) > 0:
+		roots = p.doc.Scenes[0].Nodes
+	default:
+		// No scene, every mesh as is
+		for i := range p.doc.Meshes {
+			p.addMesh(i, identity4)
+		}
+	}
+	for _, root := range roots {
+		p.walk(root, identity4, 0)
+	}
+
+	if len(p.mesh.Faces) == 0 {
+		return nil, fmt.Errorf("%s: no triangle found", name)
+	}
+	return p.mesh, nil
+}
+
+func splitGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if len(data) < 20 {
+		return nil, nil, fmt.Errorf("glb too short")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != 2 {
+		return nil, nil, fmt.Errorf("glb version %d is not supported", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:12]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("glb length %d exceeds file size %d", length, len(data))
+	}
+
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		start := offset + 8
+		if start+chunkLength > length {
+			return nil, nil, fmt.Errorf("glb chunk %q exceeds file", chunkType)
+		}
+		switch chunkType {
+		case "JSON":
+			jsonChunk = data[start : start+chunkLength]
+		case "BIN\x00":
+			binChunk = data[start : start+chunkLength]
+		}
+		offset = start + chunkLength
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("glb has no JSON chunk")
+	}
+	return
+}
+
+// Read uri of data:, relative file
+func (p *gltfParser) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 {
+			return nil, fmt.Errorf("broken data uri")
+		}
+		if !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("data uri must be base64")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	path, err := url.PathUnescape(uri)
+	if err != nil {
+		path = uri
+	}
+	return os.ReadFile(filepath.Join(p.directory, filepath.FromSlash(path)))
+}
+
+func (p *gltfParser) loadBuffers(binChunk []byte) error {
+	p.buffers = make([][]byte, len(p.doc.Buffers))
+	for i, buffer := range p.doc.Buffers {
+		if buffer.URI == "" {
+			// GLB-stored buffer
+			if i != 0 || binChunk == nil {
+				return fmt.Errorf("buffer %d has no uri", i)
+			}
+			p.buffers[i] = binChunk
+			continue
+		}
+		data, err := p.readURI(buffer.URI)
+		if err != nil {
+			return fmt.Errorf("buffer %d: %w", i, err)
+		}
+		p.buffers[i] = data
+	}
+	return nil
+}
+
+func (p *gltfParser) bufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(p.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("buffer view %d out of range", index)
+	}
+	view := p.doc.BufferViews[index]
+	if view.Buffer < 0 || view.Buffer >= len(p.buffers) {
+		return nil, 0, fmt.Errorf("buffer %d out of range", view.Buffer)
+	}
+	buffer := p.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteStride < 0 {
+		return nil, 0, fmt.Errorf("buffer view %d has negative offset, length or stride", index)
+	}
+	if view.ByteOffset+view.ByteLength > len(buffer) {
+		return nil, 0, fmt.Errorf("buffer view %d exceeds buffer", index)
+	}
+	return buffer[view.ByteOffset : view.ByteOffset+view.ByteLength], view.ByteStride, nil
+}
+
+// Accessor as float vectors(normalized integers are mapped to 0..1)
+func (p *gltfParser) accessor(index int) ([][]float64, error) {
+	if index < 0 || index >= len(p.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d out of range", index)
+	}
+	a := p.doc.Accessors[index]
+	if a.Sparse != nil {
+		return nil, fmt.Errorf("accessor %d: sparse accessor is not supported", index)
+	}
+	components := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}[a.Type]
+	if components == 0 {
+		return nil, fmt.Errorf("accessor %d: type %q is not supported", index, a.Type)
+	}
+	size := map[int]int{5120: 1, 5121: 1, 5122: 2, 5123: 2, 5125: 4, 5126: 4}[a.ComponentType]
+	if size == 0 {
+		return nil, fmt.Errorf("accessor %d: component type %d is not supported", index, a.ComponentType)
+	}
+	if a.Count < 0 || a.ByteOffset < 0 {
+		return nil, fmt.Errorf("accessor %d: negative count or offset", index)
+	}
+
+	values := make([][]float64, a.Count)
+	if a.BufferView == nil {
+		// All zero
+		for i := range values {
+			values[i] = make([]float64, components)
+		}
+		return values, nil
+	}
+	view, stride, err := p.bufferView(*a.BufferView)
+	if err != nil {
+		return nil, fmt.Errorf("accessor %d: %w", index, err)
+	}
+	if stride == 0 {
+		stride = size * components
+	}
+	if a.Count > 0 && a.ByteOffset+(a.Count-1)*stride+size*components > len(view) {
+		return nil, fmt.Errorf("accessor %d exceeds buffer view", index)
+	}
+
+	for i := range values {
+		values[i] = make([]float64, components)
+		for c := 0; c < components; c++ {
+			b := view[a.ByteOffset+i*stride+c*size:]
+			var v float64
+			switch a.ComponentType {
+			case 5120:
+				v = float64(int8(b[0]))
+				if a.Normalized {
+					v = math.Max(v/127, -1)
+				}
+			case 5121:
+				v = float64(b[0])
+				if a.Normalized {
+					v /= 255
+				}
+			case 5122:
+				v = float64(int16(binary.LittleEndian.Uint16(b)))
+				if a.Normalized {
+					v = math.Max(v/32767, -1)
+				}
+			case 5123:
+				v = float64(binary.LittleEndian.Uint16(b))
+				if a.Normalized {
+					v /= 65535
+				}
+			case 5125:
+				v = float64(binary.LittleEndian.Uint32(b))
+			case 5126:
+				v = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+			}
+			values[i][c] = v
+		}
+	}
+	return values, nil
+}
+
+// Primitives without material
+const gltfDefaultMaterial = "gltf:default"
+
+func gltfMaterialName(index int) string {
+	return fmt.Sprintf("gltf:%d", index)
+}
+
+func (p *gltfParser) loadMaterials() {
+	for i, m := range p.doc.Materials {
+		material := &Material{Name: m.Name, TintAlways: true}
+		p.mesh.Materials[gltfMaterialName(i)] = material
+
+		pbr := m.PbrMetallicRoughness
+		if pbr == nil {
+			// glTF default base color is white
+			material.Diffuse, material.HasDiffuse = [3]float64{1, 1, 1}, true
+			continue
+		}
+		material.Diffuse, material.HasDiffuse = [3]float64{1, 1, 1}, true
+		if len(pbr.BaseColorFactor) >= 3 {
+			// Factor is linear, texels and Kd are sRGB
+			for c := range material.Diffuse {
+				material.Diffuse[c] = linearToSRGB(pbr.BaseColorFactor[c])
+			}
+		}
+		if pbr.BaseColorTexture != nil {
+			if pbr.BaseColorTexture.TexCoord != 0 {
+				p.report.Add("object", p.name, 0, fmt.Errorf("material %d: TEXCOORD_%d is not supported, TEXCOORD_0 is used", i, pbr.BaseColorTexture.TexCoord))
+			}
+			texture, err := p.texture(pbr.BaseColorTexture.Index)
+			if err != nil {
+				p.report.Add("mtl", p.name, 0, fmt.Errorf("material %d: %w", i, err))
+				continue
+			}
+			material.Texture = texture
+		}
+	}
+}
+
+func linearToSRGB(v float64) float64 {
+	v = clamp01(v)
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func (p *gltfParser) texture(index int) (Texture, error) {
+	if texture, ok := p.textures[index]; ok {
+		return texture, nil
+	}
+	if index < 0 || index >= len(p.doc.Textures) || p.doc.Textures[index].Source == nil {
+		return nil, fmt.Errorf("texture %d has no image", index)
+	}
+	source := *p.doc.Textures[index].Source
+	if source < 0 || source >= len(p.doc.Images) {
+		return nil, fmt.Errorf("image %d out of range", source)
+	}
+	img := p.doc.Images[source]
+
+	var data []byte
+	var err error
+	if img.BufferView != nil {
+		data, _, err = p.bufferView(*img.BufferView)
+	} else {
+		data, err = p.readURI(img.URI)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("image %d: %w", source, err)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image %d: decode: %w", source, err)
+	}
+	texture, err := NewTexture(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("image %d: %w", source, err)
+	}
+	p.textures[index] = texture
+	return texture, nil
+}
+
+// Column major 4x4 matrix
+type matrix4 [16]float64
+
+var identity4 = matrix4{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+
+func (a matrix4) mul(b matrix4) (m matrix4) {
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			for k := 0; k < 4; k++ {
+				m[col*4+row] += a[k*4+row] * b[col*4+k]
+			}
+		}
+	}
+	return
+}
+
+func (a matrix4) point(v [3]float64) [3]float64 {
+	return [3]float64{
+		a[0]*v[0] + a[4]*v[1] + a[8]*v[2] + a[12],
+		a[1]*v[0] + a[5]*v[1] + a[9]*v[2] + a[13],
+		a[2]*v[0] + a[6]*v[1] + a[10]*v[2] + a[14],
+	}
+}
+
+func (a matrix4) direction(v [3]float64) [3]float64 {
+	return [3]float64{
+		a[0]*v[0] + a[4]*v[1] + a[8]*v[2],
+		a[1]*v[0] + a[5]*v[1] + a[9]*v[2],
+		a[2]*v[0] + a[6]*v[1] + a[10]*v[2],
+	}
+}
+
+// Normal by the inverse transpose of the linear part, keeps normals perpendicular under non-uniform scale
+func (a matrix4) normal(v [3]float64) [3]float64 {
+	det := a.determinant()
+	if det == 0 {
+		return a.direction(v)
+	}
+	// Cofactor matrix / determinant
+	return [3]float64{
+		((a[5]*a[10]-a[9]*a[6])*v[0] + (a[2]*a[9]-a[1]*a[10])*v[1] + (a[1]*a[6]-a[2]*a[5])*v[2]) / det,
+		((a[8]*a[6]-a[4]*a[10])*v[0] + (a[0]*a[10]-a[2]*a[8])*v[1] + (a[4]*a[2]-a[0]*a[6])*v[2]) / det,
+		((a[4]*a[9]-a[8]*a[5])*v[0] + (a[8]*a[1]-a[0]*a[9])*v[1] + (a[0]*a[5]-a[4]*a[1])*v[2]) / det,
+	}
+}
+
+// Determinant of the linear part
+func (a matrix4) determinant() float64 {
+	return a[0]*(a[5]*a[10]-a[9]*a[6]) - a[4]*(a[1]*a[10]-a[9]*a[2]) + a[8]*(a[1]*a[6]-a[5]*a[2])
+}
+
+// Negative determinant mirrors the faces
+func (a matrix4) mirrored() bool {
+	return a.determinant() < 0
+}
+
+func (p *gltfParser) walk(index int, parent matrix4, depth int) {
+	if index < 0 || index >= len(p.doc.Nodes) || depth > 256 {
+		p.report.Add("object", p.name, 0, fmt.Errorf("node %d: broken hierarchy", index))
+		return
+	}
+	node := p.doc.Nodes[index]
+
+	local := identity4
+	if len(node.Matrix) == 16 {
+		copy(local[:], node.Matrix)
+	} else {
+		t, r, s := [3]float64{}, [4]float64{0, 0, 0, 1}, [3]float64{1, 1, 1}
+		copy(t[:], node.Translation)
+		copy(r[:], node.Rotation)
+		copy(s[:], node.Scale)
+		rotation := quaternionMatrix([4]float64{r[3], r[0], r[1], r[2]})
+		for col := 0; col < 3; col++ {
+			for row := 0; row < 3; row++ {
+				local[col*4+row] = rotation[row][col] * s[col]
+			}
+		}
+		local[12], local[13], local[14] = t[0], t[1], t[2]
+	}
+	world := parent.mul(local)
+
+	if node.Mesh != nil {
+		p.addMesh(*node.Mesh, world)
+	}
+	for _, child := range node.Children {
+		p.walk(child, world, depth+1)
+	}
+}
+
+func (p *gltfParser) addMesh(index int, world matrix4) {
+	if index < 0 || index >= len(p.doc.Meshes) {
+		p.report.Add("object", p.name, 0, fmt.Errorf("mesh %d out of range", index))
+		return
+	}
+	for i := range p.doc.Meshes[index].Primitives {
+		if err := p.addPrimitive(index, i, world); err != nil {
+			p.report.Add("object", p.name, 0, fmt.Errorf("mesh %d primitive %d: skip: %w", index, i, err))
+		}
+	}
+}
+
+func (p *gltfParser) addPrimitive(meshIndex, primitiveIndex int, world matrix4) error {
+	primitive := p.doc.Meshes[meshIndex].Primitives[primitiveIndex]
+	mode := 4
+	if primitive.Mode != nil {
+		mode = *primitive.Mode
+	}
+	if mode < 4 {
+		return fmt.Errorf("mode %d(points/lines) has no surface", mode)
+	}
+
+	positionIndex, ok := primitive.Attributes["POSITION"]
+	if !ok {
+		return fmt.Errorf("no POSITION")
+	}
+	positions, err := p.accessor(positionIndex)
+	if err != nil {
+		return err
+	}
+	if len(positions) > 0 && len(positions[0]) < 3 {
+		return fmt.Errorf("POSITION is not VEC3")
+	}
+	uvs, err := p.attribute(primitive.Attributes, "TEXCOORD_0", 2, len(positions), meshIndex, primitiveIndex)
+	if err != nil {
+		return err
+	}
+	normals, err := p.attribute(primitive.Attributes, "NORMAL", 3, len(positions), meshIndex, primitiveIndex)
+	if err != nil {
+		return err
+	}
+
+	indices := make([]int, len(positions))
+	for i := range indices {
+		indices[i] = i
+	}
+	if primitive.Indices != nil {
+		values, err := p.accessor(*primitive.Indices)
+		if err != nil {
+			return err
+		}
+		// Indices are unsigned integer scalars
+		a := p.doc.Accessors[*primitive.Indices]
+		if a.Type != "SCALAR" || (a.ComponentType != 5121 && a.ComponentType != 5123 && a.ComponentType != 5125) {
+			return fmt.Errorf("indices accessor %d: %s of component type %d is not an unsigned integer scalar", *primitive.Indices, a.Type, a.ComponentType)
+		}
+		indices = indices[:0]
+		for _, v := range values {
+			if int(v[0]) >= len(positions) {
+				return fmt.Errorf("index %d out of range", int(v[0]))
+			}
+			indices = append(indices, int(v[0]))
+		}
+	}
+
+	vertexBase, uvBase, normalBase := len(p.mesh.Vertices), len(p.mesh.UVs), len(p.mesh.Normals)
+	for _, v := range positions {
+		p.mesh.Vertices = append(p.mesh.Vertices, world.point([3]float64{v[0], v[1], v[2]}))
+	}
+	for _, uv := range uvs {
+		p.mesh.UVs = append(p.mesh.UVs, [2]float64{uv[0], uv[1]})
+	}
+	for _, n := range normals {
+		p.mesh.Normals = append(p.mesh.Normals, world.normal([3]float64{n[0], n[1], n[2]}))
+	}
+
+	material := gltfDefaultMaterial
+	if primitive.Material != nil {
+		material = gltfMaterialName(*primitive.Material)
+	} else if _, ok := p.mesh.Materials[material]; !ok {
+		// glTF default material is white
+		p.mesh.Materials[material] = &Material{Name: material, Diffuse: [3]float64{1, 1, 1}, HasDiffuse: true, TintAlways: true}
+	}
+
+	// Triangle list/strip/fan
+	var triangles [][3]int
+	switch mode {
+	case 4:
+		for i := 0; i+2 < len(indices); i += 3 {
+			triangles = append(triangles, [3]int{indices[i], indices[i+1], indices[i+2]})
+		}
+	case 5:
+		for i := 0; i+2 < len(indices); i++ {
+			if i%2 == 0 {
+				triangles = append(triangles, [3]int{indices[i], indices[i+1], indices[i+2]})
+			} else {
+				triangles = append(triangles, [3]int{indices[i+1], indices[i], indices[i+2]})
+			}
+		}
+	case 6:
+		for i := 1; i+1 < len(indices); i++ {
+			triangles = append(triangles, [3]int{indices[0], indices[i], indices[i+1]})
+		}
+	default:
+		return fmt.Errorf("mode %d is not supported", mode)
+	}
+
+	mirrored := world.mirrored()
+	for _, tri := range triangles {
+		if mirrored {
+			tri[1], tri[2] = tri[2], tri[1]
+		}
+		face := Face{
+			UV:       [3]int{-1, -1, -1},
+			Normal:   [3]int{-1, -1, -1},
+			Material: material,
+			Object:   p.doc.Meshes[meshIndex].Name,
+			Group:    fmt.Sprintf("mesh %d primitive %d", meshIndex, primitiveIndex),
+		}
+		for i, index := range tri {
+			face.Vertex[i] = vertexBase + index
+			if uvs != nil {
+				face.UV[i] = uvBase + index
+			}
+			if normals != nil {
+				face.Normal[i] = normalBase + index
+			}
+		}
+		p.mesh.Faces = append(p.mesh.Faces, face)
+	}
+	return nil
+}
+
+// Optional vertex attribute, dropped with a report when it has fewer components or elements than POSITION
+func (p *gltfParser) attribute(attributes map[string]int, name string, components, count, meshIndex, primitiveIndex int) ([][]float64, error) {
+	index, ok := attributes[name]
+	if !ok {
+		return nil, nil
+	}
+	values, err := p.accessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > 0 && len(values[0]) < components {
+		p.report.Add("object", p.name, 0, fmt.Errorf("mesh %d primitive %d: %s has %d components, ignored", meshIndex, primitiveIndex, name, len(values[0])))
+		return nil, nil
+	}
+	if len(values) < count {
+		p.report.Add("object", p.name, 0, fmt.Errorf("mesh %d primitive %d: %s has %d elements for %d positions, ignored", meshIndex, primitiveIndex, name, len(values), count))
+		return nil, nil
+	}
+	return values, nil
+}
@@ -0,0 +1,169 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// One triangle with NORMAL and a TEXCOORD_0 of uvCount elements, under a node scaled by (2, 1, 1)
+func sampleGLTF(uvCount int) string {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	n := float32(1 / math.Sqrt2)
+	binary.Write(&b, binary.LittleEndian, []float32{n, n, 0, n, n, 0, n, n, 0})
+	for i := 0; i < uvCount; i++ {
+		binary.Write(&b, binary.LittleEndian, []float32{0, 0})
+	}
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+
+	return fmt.Sprintf(`{
+  "scenes": [{"nodes": [0]}],
+  "nodes": [{"mesh": 0, "scale": [2, 1, 1]}],
+  "meshes": [{"name": "tri", "primitives": [{"attributes": {"POSITION": 0, "NORMAL": 1, "TEXCOORD_0": 2}, "material": 0}]}],
+  "materials": [{"pbrMetallicRoughness": {"baseColorFactor": [0.5, 0.5, 0.5, 1]}}],
+  "accessors": [
+    {"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+    {"bufferView": 1, "componentType": 5126, "count": 3, "type": "VEC3"},
+    {"bufferView": 2, "componentType": 5126, "count": %d, "type": "VEC2"}
+  ],
+  "bufferViews": [
+    {"buffer": 0, "byteOffset": 0, "byteLength": 36},
+    {"buffer": 0, "byteOffset": 36, "byteLength": 36},
+    {"buffer": 0, "byteOffset": 72, "byteLength": %d}
+  ],
+  "buffers": [{"uri": %q, "byteLength": %d}]
+}`, uvCount, uvCount*8, uri, b.Len())
+}
+
+func TestParseGLTF(t *testing.T) {
+	m, err := ParseGLTF(strings.NewReader(sampleGLTF(3)), "tri.gltf", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 1 || len(m.UVs) != 3 {
+		t.Fatalf("faces %d uvs %d, want 1 3", len(m.Faces), len(m.UVs))
+	}
+	face := m.Faces[0]
+	if face.Line != 0 || face.Object != "tri" || face.Group != "mesh 0 primitive 0" || !face.HasUV() {
+		t.Errorf("face %+v", face)
+	}
+	if v := m.Vertices[1]; v != [3]float64{2, 0, 0} {
+		t.Errorf("scaled vertex %v, want [2 0 0]", v)
+	}
+
+	// Normal of the x+y=c plane scaled by (2, 1, 1) is perpendicular to x+2y=2c: (1, 2, 0)
+	normal := m.Normals[0]
+	if math.Abs(normal[1]-2*normal[0]) > 1e-6 || normal[2] != 0 {
+		t.Errorf("normal %v, want parallel to (1, 2, 0)", normal)
+	}
+
+	// Linear 0.5 is sRGB 0.735
+	material := m.Materials[gltfMaterialName(0)]
+	if d := material.Diffuse[0]; math.Abs(d-0.7354) > 1e-3 {
+		t.Errorf("diffuse %f, want 0.7354", d)
+	}
+}
+
+func TestParseGLTFShortAttribute(t *testing.T) {
+	report := diagnostic.NewReport(nil)
+	m, err := ParseGLTF(strings.NewReader(sampleGLTF(1)), "short.gltf", "", report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.UVs) != 0 || m.Faces[0].HasUV() {
+		t.Errorf("short TEXCOORD_0 is kept: uvs %d face %+v", len(m.UVs), m.Faces[0])
+	}
+	items := report.Items()
+	if len(items) != 1 || items[0].Line != 0 || !strings.Contains(items[0].Err.Error(), "TEXCOORD_0") {
+		t.Errorf("reports %v, want one TEXCOORD_0 report", items)
+	}
+}
+
+func TestGLTFBaseColorFactor(t *testing.T) {
+	m, err := ParseGLTF(strings.NewReader(sampleGLTF(3)), "tri.gltf", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// White baseColorTexture
+	m.Materials[gltfMaterialName(0)].Texture = Texture{{palette.Color{R: 255, G: 255, B: 255}}}
+
+	// baseColorFactor applies without TintTexture(.obj only)
+	samples, err := m.SampleFace(m.Faces[0], SurfaceOptions{GridSpacing: 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) == 0 {
+		t.Fatal("no sample")
+	}
+	for _, s := range samples {
+		if s.Color != (palette.Color{R: 188, G: 188, B: 188}) {
+			t.Fatalf("color %v, want white × sRGB 0.735", s.Color)
+		}
+	}
+}
+
+// One triangle with uint16 indices, accessor 1 fields are replaced by indices
+func indexedGLTF(indices string) string {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(&b, binary.LittleEndian, []uint16{0, 1, 2, 0})
+	uri := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+
+	return fmt.Sprintf(`{
+  "meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}],
+  "accessors": [
+    {"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+    {"bufferView": 1, %s}
+  ],
+  "bufferViews": [
+    {"buffer": 0, "byteOffset": 0, "byteLength": 36},
+    {"buffer": 0, "byteOffset": 36, "byteLength": 8}
+  ],
+  "buffers": [{"uri": %q, "byteLength": %d}]
+}`, indices, uri, b.Len())
+}
+
+func TestParseGLTFIndices(t *testing.T) {
+	tests := []struct {
+		name    string
+		indices string
+		err     string
+	}{
+		{"uint16", `"componentType": 5123, "count": 3, "type": "SCALAR"`, ""},
+		{"negative count", `"componentType": 5123, "count": -3, "type": "SCALAR"`, "negative count"},
+		{"negative offset", `"componentType": 5123, "count": 3, "type": "SCALAR", "byteOffset": -2`, "negative count or offset"},
+		{"float", `"componentType": 5126, "count": 2, "type": "SCALAR"`, "not an unsigned integer"},
+		{"signed", `"componentType": 5122, "count": 3, "type": "SCALAR"`, "not an unsigned integer"},
+	}
+	for _, tt := range tests {
+		report := diagnostic.NewReport(nil)
+		m, err := ParseGLTF(strings.NewReader(indexedGLTF(tt.indices)), tt.name+".gltf", "", report)
+		if tt.err == "" {
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			// No material: glTF default white
+			material := m.Materials[m.Faces[0].Material]
+			if c, _ := material.Color(); c != (palette.Color{R: 255, G: 255, B: 255}) {
+				t.Errorf("%s: default material %v, want white", tt.name, c)
+			}
+			continue
+		}
+		// The only primitive is skipped with a report
+		if err == nil {
+			t.Errorf("%s: parsed", tt.name)
+		}
+		items := report.Items()
+		if len(items) != 1 || !strings.Contains(items[0].Err.Error(), tt.err) {
+			t.Errorf("%s: reports %v, want %q", tt.name, items, tt.err)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package mesh
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+type modelFace struct {
+	UV       []float64 `json:"uv"` // u1, v1, u2, v2 in pixels
+	Texture  string    `json:"texture"`
+	Rotation int       `json:"rotation"`
+}
+
+type modelElement struct {
+	From     [3]float64 `json:"from"`
+	To       [3]float64 `json:"to"`
+	Rotation *struct {
+		Origin  [3]float64 `json:"origin"`
+		Axis    string     `json:"axis"`
+		Angle   float64    `json:"angle"`
+		Rescale bool       `json:"rescale"`
+	} `json:"rotation"`
+	Faces map[string]modelFace `json:"faces"`
+}
+
+type modelFile struct {
+	Parent   string            `json:"parent"`
+	Textures map[string]string `json:"textures"`
+	Elements []modelElement    `json:"elements"`
+}
+
+var modelDirections = []string{"north", "south", "west", "east", "up", "down"}
+
+// Corners of each face counterclockwise seen from outside(outward normal): top left, bottom left, bottom right, top right
+// Index bit: 0 x(from/to), 1 y, 2 z
+var modelFaceCorners = map[string][4]int{
+	"north": {0b011, 0b001, 0b000, 0b010},
+	"south": {0b110, 0b100, 0b101, 0b111},
+	"west":  {0b010, 0b000, 0b100, 0b110},
+	"east":  {0b111, 0b101, 0b001, 0b011},
+	"up":    {0b010, 0b110, 0b111, 0b011},
+	"down":  {0b100, 0b000, 0b001, 0b101},
+}
+
+// Parse Minecraft Java block/item model(elements or builtin/generated) in pixels(16 per block)
+//
+// directory: assets directory containing "<namespace>/models" and "<namespace>/textures"
+func ParseModel(directory string, model palette.Resource, report *diagnostic.Report) (*Mesh, error) {
+	name := model.Namespace + ":" + model.Path
+	textures := map[string]string{}
+	var elements []modelElement
+	generated := false
+
+	// Parent chain, child textures and elements win
+	resource := model
+	for depth := 0; ; depth++ {
+		if depth > 64 {
+			return nil, fmt.Errorf("%s: parent chain too deep", name)
+		}
+		file := filepath.Join(directory, resource.Namespace, "models", resource.Path+".json")
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var m modelFile
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("%s: parse %s: %w", name, file, err)
+		}
+		for key, value := range m.Textures {
+			if _, ok := textures[key]; !ok {
+				textures[key] = value
+			}
+		}
+		if elements == nil && m.Elements != nil {
+			elements = m.Elements
+		}
+
+		switch strings.TrimPrefix(m.Parent, "minecraft:") {
+		case "":
+		case "builtin/generated":
+			generated = elements == nil
+		case "builtin/entity":
+			return nil, fmt.Errorf("%s: builtin/entity model has no geometry", name)
+		default:
+			resource = palette.ParseResource(m.Parent)
+			continue
+		}
+		break
+	}
+
+	b := &modelBuilder{
+		directory: directory,
+		name:      name,
+		textures:  textures,
+		report:    report,
+		materials: map[string]*Material{},
+		mesh: &Mesh{
+			Name:      name,
+			Materials: map[string]*Material{},
+			UVTopLeft: true,
+		},
+	}
+	if generated {
+		b.generate()
+	} else {
+		for i, element := range elements {
+			b.addElement(element, i)
+		}
+	}
+
+	if len(b.mesh.Faces) == 0 {
+		return nil, fmt.Errorf("%s: model has no face", name)
+	}
+	return b.mesh, nil
+}
+
+type modelBuilder struct {
+	directory string
+	name      string
+	textures  map[string]string
+	report    *diagnostic.Report
+	materials map[string]*Material // Loaded by texture variable
+	mesh      *Mesh
+}
+
+// Resolve "#variable" to texture resource
+func (b *modelBuilder) resolve(texture string) (string, error) {
+	for depth := 0; strings.HasPrefix(texture, "#"); depth++ {
+		value, ok := b.textures[texture[1:]]
+		if !ok || depth > 64 {
+			return "", fmt.Errorf("texture %s is not defined", texture)
+		}
+		texture = value
+	}
+	return texture, nil
+}
+
+func (b *modelBuilder) loadImage(texture string) (image.Image, error) {
+	resource, err := b.resolve(texture)
+	if err != nil {
+		return nil, err
+	}
+	path := palette.TexturePath(b.directory, palette.ParseResource(resource))
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	// First frame of animated texture
+	bounds := img.Bounds()
+	if w, h := bounds.Dx(), bounds.Dy(); w > 0 && h > w && h%w == 0 {
+		if sub, ok := img.(interface {
+			SubImage(image.Rectangle) image.Image
+		}); ok {
+			img = sub.SubImage(image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Min.Y+w))
+		}
+	}
+	return img, nil
+}
+
+func (b *modelBuilder) material(texture string) string {
+	if _, ok := b.materials[texture]; ok {
+		return texture
+	}
+	material := &Material{Name: texture}
+	b.materials[texture] = material
+	b.mesh.Materials[texture] = material
+
+	img, err := b.loadImage(texture)
+	if err != nil {
+		b.report.Add("object", b.name, 0, err)
+		return texture
+	}
+	if material.Texture, err = NewTexture(img); err != nil {
+		b.report.Add("object", b.name, 0, err)
+		return texture
+	}
+	material.Mask = NewMask(img)
+	return texture
+}
+
+// index: "elements" index, faces are named "element N"(no source line)
+func (b *modelBuilder) addElement(element modelElement, index int) {
+	// Box corners, rotated around origin
+	var corners [8][3]float64
+	for i := range corners {
+		for axis := 0; axis < 3; axis++ {
+			corners[i][axis] = element.From[axis]
+			if i>>axis&1 == 1 {
+				corners[i][axis] = element.To[axis]
+			}
+		}
+	}
+	if r := element.Rotation; r != nil && r.Angle != 0 {
+		axis := map[string]int{"x": 0, "y": 1, "z": 2}[r.Axis]
+		u, v := (axis+1)%3, (axis+2)%3
+		sin, cos := math.Sincos(r.Angle * math.Pi / 180)
+		scale := 1.0
+		if r.Rescale {
+			scale = 1 / cos
+		}
+		for i := range corners {
+			du, dv := corners[i][u]-r.Origin[u], corners[i][v]-r.Origin[v]
+			corners[i][u] = r.Origin[u] + (du*cos-dv*sin)*scale
+			corners[i][v] = r.Origin[v] + (du*sin+dv*cos)*scale
+		}
+	}
+
+	for _, direction := range modelDirections {
+		face, ok := element.Faces[direction]
+		if !ok {
+			continue
+		}
+		var quad [4][3]float64
+		for i, corner := range modelFaceCorners[direction] {
+			quad[i] = corners[corner]
+		}
+		if area := cross(sub(quad[1], quad[0]), sub(quad[3], quad[0])); dot(area, area) == 0 {
+			continue
+		}
+
+		uv := face.UV
+		if len(uv) != 4 {
+			uv = defaultModelUV(direction, element.From, element.To)
+		}
+		corner := [4][2]float64{{uv[0], uv[1]}, {uv[0], uv[3]}, {uv[2], uv[3]}, {uv[2], uv[1]}}
+		// Rotate texture clockwise
+		steps := ((face.Rotation / 90 % 4) + 4) % 4
+
+		vertexBase, uvBase := len(b.mesh.Vertices), len(b.mesh.UVs)
+		for i := 0; i < 4; i++ {
+			b.mesh.Vertices = append(b.mesh.Vertices, quad[i])
+			c := corner[(i+steps)%4]
+			// Keep u, v < 1 not to wrap to the next texture repeat
+			b.mesh.UVs = append(b.mesh.UVs, [2]float64{min(c[0]/16, 1-1e-9), min(c[1]/16, 1-1e-9)})
+		}
+		material := b.material(face.Texture)
+		for _, tri := range [2][3]int{{0, 1, 2}, {0, 2, 3}} {
+			b.mesh.Faces = append(b.mesh.Faces, Face{
+				Group:    fmt.Sprintf("element %d %s", index, direction),
+				Vertex:   [3]int{vertexBase + tri[0], vertexBase + tri[1], vertexBase + tri[2]},
+				UV:       [3]int{uvBase + tri[0], uvBase + tri[1], uvBase + tri[2]},
+				Normal:   [3]int{-1, -1, -1},
+				Material: material,
+			})
+		}
+	}
+}
+
+// UV from element position when face has no "uv"
+func defaultModelUV(direction string, from, to [3]float64) []float64 {
+	switch direction {
+	case "north":
+		return []float64{16 - to[0], 16 - to[1], 16 - from[0], 16 - from[1]}
+	case "south":
+		return []float64{from[0], 16 - to[1], to[0], 16 - from[1]}
+	case "west":
+		return []float64{from[2], 16 - to[1], to[2], 16 - from[1]}
+	case "east":
+		return []float64{16 - to[2], 16 - to[1], 16 - from[2], 16 - from[1]}
+	case "up":
+		return []float64{from[0], from[2], to[0], to[2]}
+	default:
+		return []float64{from[0], 16 - to[2], to[0], 16 - from[2]}
+	}
+}
+
+// Extrude layer0, layer1... of item model 1 pixel thick, upper layers win
+func (b *modelBuilder) generate() {
+	var layers []image.Image
+	for i := 0; ; i++ {
+		layer := fmt.Sprintf("layer%d", i)
+		if _, ok := b.textures[layer]; !ok {
+			break
+		}
+		img, err := b.loadImage("#" + layer)
+		if err != nil {
+			b.report.Add("object", b.name, 0, err)
+			continue
+		}
+		layers = append(layers, img)
+	}
+	if len(layers) == 0 {
+		return
+	}
+
+	size := layers[0].Bounds().Dx()
+	pixel := 16 / float64(size)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			color, opaque := [3]float64{}, false
+			for _, img := range layers {
+				bounds := img.Bounds()
+				// Scale to layer0 resolution
+				px := bounds.Min.X + x*bounds.Dx()/size
+				py := bounds.Min.Y + y*bounds.Dx()/size
+				r, g, bl, a := img.At(px, py).RGBA()
+				if a < 0x8000 {
+					continue
+				}
+				color, opaque = [3]float64{float64(r) / 0xffff, float64(g) / 0xffff, float64(bl) / 0xffff}, true
+			}
+			if !opaque {
+				continue
+			}
+
+			from := [3]float64{float64(x) * pixel, 16 - float64(y+1)*pixel, 8 - pixel/2}
+			to := [3]float64{from[0] + pixel, from[1] + pixel, 8 + pixel/2}
+			b.addBox(from, to, b.mesh.colorMaterial(color), fmt.Sprintf("pixel %d,%d", x, y))
+		}
+	}
+}
+
+// Solid colored box, group names the source(no source line)
+func (b *modelBuilder) addBox(from, to [3]float64, material, group string) {
+	vertexBase := len(b.mesh.Vertices)
+	for i := 0; i < 8; i++ {
+		var v [3]float64
+		for axis := 0; axis < 3; axis++ {
+			v[axis] = from[axis]
+			if i>>axis&1 == 1 {
+				v[axis] = to[axis]
+			}
+		}
+		b.mesh.Vertices = append(b.mesh.Vertices, v)
+	}
+	for _, direction := range modelDirections {
+		corners := modelFaceCorners[direction]
+		for _, tri := range [2][3]int{{0, 1, 2}, {0, 2, 3}} {
+			b.mesh.Faces = append(b.mesh.Faces, Face{
+				Group:    group,
+				Vertex:   [3]int{vertexBase + corners[tri[0]], vertexBase + corners[tri[1]], vertexBase + corners[tri[2]]},
+				UV:       [3]int{-1, -1, -1},
+				Normal:   [3]int{-1, -1, -1},
+				Material: material,
+			})
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package mesh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Assets directory with one model file
+func writeModel(t *testing.T, path, json string) string {
+	t.Helper()
+	directory := t.TempDir()
+	file := filepath.Join(directory, "minecraft", "models", filepath.FromSlash(path)+".json")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(json), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return directory
+}
+
+func TestParseModelOutwardNormals(t *testing.T) {
+	directory := writeModel(t, "block/cube", `{
+  "elements": [{"from": [0, 0, 0], "to": [16, 16, 16], "faces": {
+    "north": {"texture": "#all"}, "south": {"texture": "#all"}, "west": {"texture": "#all"},
+    "east": {"texture": "#all"}, "up": {"texture": "#all"}, "down": {"texture": "#all"}
+  }}]
+}`)
+	m, err := ParseModel(directory, palette.ParseResource("block/cube"), diagnostic.NewReport(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 12 {
+		t.Fatalf("%d faces, want 12", len(m.Faces))
+	}
+
+	want := map[string][3]float64{
+		"north": {0, 0, -1}, "south": {0, 0, 1}, "west": {-1, 0, 0},
+		"east": {1, 0, 0}, "up": {0, 1, 0}, "down": {0, -1, 0},
+	}
+	for i, face := range m.Faces {
+		direction := modelDirections[i/2]
+		a, b, c := m.Vertices[face.Vertex[0]], m.Vertices[face.Vertex[1]], m.Vertices[face.Vertex[2]]
+		n := cross(sub(b, a), sub(c, a))
+		if dot(n, want[direction]) <= 0 {
+			t.Errorf("%s face %d normal %v, want along %v", direction, i, n, want[direction])
+		}
+		// Element faces are named, not lines
+		if face.Line != 0 || face.Group != "element 0 "+direction {
+			t.Errorf("face %d line %d group %q, want 0 %q", i, face.Line, face.Group, "element 0 "+direction)
+		}
+	}
+
+	// Top left of up face(north west corner) keeps uv 0,0, bottom right(south east) 16,16
+	up := m.Faces[8]
+	if v, uv := m.Vertices[up.Vertex[0]], m.UVs[up.UV[0]]; v != [3]float64{0, 16, 0} || uv != [2]float64{0, 0} {
+		t.Errorf("up top left %v uv %v, want [0 16 0] [0 0]", v, uv)
+	}
+	if v, uv := m.Vertices[up.Vertex[2]], m.UVs[up.UV[2]]; v != [3]float64{16, 16, 16} || uv[0] < 0.99 || uv[1] < 0.99 {
+		t.Errorf("up bottom right %v uv %v, want [16 16 16] [1 1]", v, uv)
+	}
+}
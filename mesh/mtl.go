@@ -0,0 +1,249 @@
+package mesh
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+type Material struct {
+	Name       string
+	Diffuse    [3]float64 // Kd 0..1
+	HasDiffuse bool
+	Ambient    [3]float64 // Ka 0..1
+	HasAmbient bool
+	Texture    Texture // map_Kd, nil: solid color
+	Mask       Mask    // Transparent texels of Texture are not sampled, nil: opaque
+	BlockID    string  // Explicit block, skips color matching
+	TintAlways bool    // Texture is always multiplied by Diffuse(glTF baseColorFactor)
+}
+
+// Solid color of material: Kd, Ka or texture average
+func (m *Material) Color() (c palette.Color, ok bool) {
+	switch {
+	case m.HasDiffuse:
+		return floatColor(m.Diffuse), true
+	case m.HasAmbient:
+		return floatColor(m.Ambient), true
+	case m.Texture != nil:
+		return m.Texture.Average(), true
+	}
+	return c, false
+}
+
+// Multiply texel by Kd
+func (m *Material) Tint(c palette.Color) palette.Color {
+	if !m.HasDiffuse {
+		return c
+	}
+	return palette.Color{
+		R: uint8(math.Round(float64(c.R) * clamp01(m.Diffuse[0]))),
+		G: uint8(math.Round(float64(c.G) * clamp01(m.Diffuse[1]))),
+		B: uint8(math.Round(float64(c.B) * clamp01(m.Diffuse[2]))),
+	}
+}
+
+func floatColor(c [3]float64) palette.Color {
+	return palette.Color{
+		R: uint8(math.Round(clamp01(c[0]) * 255)),
+		G: uint8(math.Round(clamp01(c[1]) * 255)),
+		B: uint8(math.Round(clamp01(c[2]) * 255)),
+	}
+}
+
+func clamp01(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// directory: texture directory
+func ParseMTL(r io.Reader, name, directory string, report *diagnostic.Report) (map[string]*Material, error) {
+	// map[materialName]Material
+	materials := map[string]*Material{}
+	var current *Material
+
+	lines := newLineReader(r)
+	for lines.Scan() {
+		ln := lines.Line()
+		fields := lines.Fields()
+		if len(fields) == 0 {
+			continue
+		}
+		cmd, args := fields[0], fields[1:]
+
+		if cmd == "newmtl" { // Material Name
+			current = &Material{Name: strings.Join(args, " ")}
+			materials[current.Name] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch cmd {
+		case "Kd", "Ka": // Diffuse/Ambient color
+			{
+				if len(args) > 0 && (args[0] == "spectral" || args[0] == "xyz") {
+					report.Add("mtl", name, ln, fmt.Errorf("%s %s is not supported", cmd, args[0]))
+					continue
+				}
+				c, err := parseFloats(args, 1, 3)
+				if err != nil {
+					report.Add("mtl", name, ln, fmt.Errorf("%s: %w", cmd, err))
+					continue
+				}
+				// "Kd r" is gray
+				if len(args) < 3 {
+					c[1], c[2] = c[0], c[0]
+				}
+				if cmd == "Kd" {
+					current.Diffuse, current.HasDiffuse = c, true
+				} else {
+					current.Ambient, current.HasAmbient = c, true
+				}
+			}
+		case "map_Kd": // Material texture file
+			{
+				// Options("-o u v" etc.) are placed before file name
+				file := textureFile(args)
+				texture, err := LoadTexture(filepath.Join(directory, file))
+				if err != nil {
+					report.Add("mtl", name, ln, fmt.Errorf("material %q: %w", current.Name, err))
+					continue
+				}
+
+				current.Texture = texture
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", name, lines.Line(), err)
+	}
+
+	return materials, nil
+}
+
+// Skip map_Kd options and return file name
+func textureFile(args []string) string {
+	// map[option][least, most] arguments
+	optionArgs := map[string][2]int{
+		"-blendu": {1, 1}, "-blendv": {1, 1}, "-bm": {1, 1}, "-boost": {1, 1}, "-cc": {1, 1}, "-clamp": {1, 1},
+		"-imfchan": {1, 1}, "-texres": {1, 1}, "-mm": {2, 2}, "-o": {1, 3}, "-s": {1, 3}, "-t": {1, 3},
+	}
+	i := 0
+	for i < len(args) {
+		n, ok := optionArgs[args[i]]
+		if !ok {
+			break
+		}
+		i++
+		for j := 0; j < n[1] && i < len(args); j++ {
+			// Optional arguments are numbers
+			if _, err := strconv.ParseFloat(args[i], 64); err != nil && j >= n[0] {
+				break
+			}
+			i++
+		}
+	}
+	return strings.Join(args[i:], " ")
+}
+
+// Load texture as [x][y]Color
+func LoadTexture(texturePath string) (Texture, error) {
+	f, err := os.Open(texturePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", texturePath, err)
+	}
+	return NewTexture(img)
+}
+
+func NewTexture(img image.Image) (Texture, error) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("empty texture")
+	}
+
+	// scan image
+	texture := make(Texture, bounds.Dx())
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		yColors := make([]palette.Color, bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			yColors[y-bounds.Min.Y] = palette.Color{
+				R: uint8(r >> 8),
+				G: uint8(g >> 8),
+				B: uint8(b >> 8),
+			}
+		}
+		texture[x-bounds.Min.X] = yColors
+	}
+
+	return texture, nil
+}
+
+// Nearest texel at uv, repeated
+func (t Texture) At(u, v float64, uvYAxisUp bool) palette.Color {
+	return t.Sample(u, v, 0, Sampler{UVYAxisUp: uvYAxisUp})
+}
+
+// [x][y]opaque
+type Mask [][]bool
+
+// Opaque(alpha >= 128) texels, nil when every texel is opaque
+func NewMask(img image.Image) Mask {
+	bounds := img.Bounds()
+	mask := make(Mask, bounds.Dx())
+	transparent := false
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		mask[x-bounds.Min.X] = make([]bool, bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			mask[x-bounds.Min.X][y-bounds.Min.Y] = a >= 0x8000
+			transparent = transparent || a < 0x8000
+		}
+	}
+	if !transparent {
+		return nil
+	}
+	return mask
+}
+
+// Opaque at nearest texel of uv
+func (m Mask) At(u, v float64, s Sampler) bool {
+	if m == nil {
+		return true
+	}
+	x, y := s.nearest(u, v, len(m), len(m[0]))
+	return m[x][y]
+}
+
+func (t Texture) Average() palette.Color {
+	var red, green, blue, pixel int
+	for _, column := range t {
+		for _, c := range column {
+			red += int(c.R)
+			green += int(c.G)
+			blue += int(c.B)
+			pixel++
+		}
+	}
+	if pixel == 0 {
+		return palette.Color{}
+	}
+	return palette.Color{R: uint8(red / pixel), G: uint8(green / pixel), B: uint8(blue / pixel)}
+}
@@ -0,0 +1,351 @@
+// Package mesh parses .obj/.mtl, .stl, glTF and point clouds, and samples the surface into voxels.
+package mesh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// [x][y]Color
+type Texture [][]palette.Color
+
+// Triangle of the mesh, polygons are triangulated
+type Face struct {
+	Line     int    // Source line(1 origin), 0: no line(glTF, binary STL/PLY, Minecraft model)
+	Vertex   [3]int // Mesh.Vertices index
+	UV       [3]int // Mesh.UVs index, -1: none
+	Normal   [3]int // Mesh.Normals index, -1: none
+	Material string
+	Object   string // "o" name, glTF mesh name
+	Group    string // "g" names, glTF "mesh N primitive M", binary STL "facet N", binary PLY "face N", model "element N up"/"pixel X,Y"
+	Smooth   int    // "s" group, 0: off
+}
+
+func (f Face) HasUV() bool {
+	return f.UV[0] >= 0 && f.UV[1] >= 0 && f.UV[2] >= 0
+}
+
+type Mesh struct {
+	Name      string
+	Vertices  [][3]float64
+	UVs       [][2]float64
+	Normals   [][3]float64
+	Colors    []palette.Color // Vertex colors, empty or same length as Vertices
+	Faces     []Face
+	Materials map[string]*Material // map[materialName]Material
+	UVTopLeft bool                 // UV origin is top left(glTF), ignores SurfaceOptions.UVYAxisUp
+}
+
+// Polygon triangulation method
+type Triangulation int
+
+const (
+	EarClipping Triangulation = iota // Works with concave polygons
+	Fan                              // Convex polygons only
+)
+
+type ParseOptions struct {
+	Directory     string // .mtl & texture directory
+	Triangulation Triangulation
+}
+
+// Broken lines are reported and skipped.
+func ParseOBJ(r io.Reader, name string, opt ParseOptions, report *diagnostic.Report) (*Mesh, error) {
+	m := &Mesh{
+		Name:      name,
+		Materials: map[string]*Material{},
+	}
+	var (
+		currentTexture string
+		currentObject  string
+		currentGroup   string
+		currentSmooth  int
+		colors         []palette.Color
+		colored        int
+	)
+
+	lines := newLineReader(r)
+	for lines.Scan() {
+		ln := lines.Line()
+		fields := lines.Fields()
+		if len(fields) == 0 {
+			continue
+		}
+		cmd, args := fields[0], fields[1:]
+
+		switch cmd {
+		case "mtllib":
+			{
+				// File names may contain spaces, try whole argument first
+				files := []string{strings.Join(args, " ")}
+				if _, err := os.Stat(filepath.Join(opt.Directory, files[0])); err != nil && len(args) > 1 {
+					files = args
+				}
+				for _, file := range files {
+					m.loadMTL(file, opt.Directory, ln, report)
+				}
+			}
+		case "v": // Polygon top
+			{
+				v, err := parseFloats(args, 3, 3)
+				if err != nil {
+					report.Add("object", name, ln, fmt.Errorf("vertex: %w", err))
+				}
+				m.Vertices = append(m.Vertices, [3]float64{v[0], v[1], v[2]})
+
+				// "v x y z r g b" vertex color
+				var color palette.Color
+				if len(args) >= 6 {
+					c, err := parseFloats(args[3:6], 3, 3)
+					if err != nil {
+						report.Add("object", name, ln, fmt.Errorf("vertex color: %w", err))
+					} else {
+						color = vertexColor(c)
+						colored++
+					}
+				}
+				colors = append(colors, color)
+			}
+		case "vt": // Texture top
+			{
+				vt, err := parseFloats(args, 1, 2)
+				if err != nil {
+					report.Add("object", name, ln, fmt.Errorf("texture vertex: %w", err))
+				}
+				m.UVs = append(m.UVs, [2]float64{vt[0], vt[1]})
+			}
+		case "vn": // Normal
+			{
+				vn, err := parseFloats(args, 3, 3)
+				if err != nil {
+					report.Add("object", name, ln, fmt.Errorf("normal: %w", err))
+				}
+				m.Normals = append(m.Normals, [3]float64{vn[0], vn[1], vn[2]})
+			}
+		case "usemtl": // Set use material
+			{
+				currentTexture = strings.Join(args, " ")
+			}
+		case "o":
+			{
+				currentObject = strings.Join(args, " ")
+			}
+		case "g":
+			{
+				currentGroup = strings.Join(args, " ")
+			}
+		case "s":
+			{
+				currentSmooth = 0
+				if len(args) > 0 && args[0] != "off" {
+					currentSmooth, _ = strconv.Atoi(args[0])
+				}
+			}
+		case "f": // Object surface/polygon
+			{
+				faces, err := m.parseFace(args, opt.Triangulation)
+				if err != nil {
+					report.Add("object", name, ln, fmt.Errorf("skip face: %w", err))
+					continue
+				}
+				for _, face := range faces {
+					face.Line = ln
+					face.Material = currentTexture
+					face.Object = currentObject
+					face.Group = currentGroup
+					face.Smooth = currentSmooth
+					m.Faces = append(m.Faces, face)
+				}
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", name, lines.Line(), err)
+	}
+
+	// Vertex colors are kept only when all vertices have colors
+	if colored == len(m.Vertices) && colored > 0 {
+		m.Colors = colors
+	} else if colored > 0 {
+		report.Add("object", name, 0, fmt.Errorf("%d vertices have no color, vertex colors are ignored", len(m.Vertices)-colored))
+	}
+	return m, nil
+}
+
+// 0..1 color, 0..255 when any component is over 1
+func vertexColor(c [3]float64) palette.Color {
+	if c[0] > 1 || c[1] > 1 || c[2] > 1 {
+		c = [3]float64{c[0] / 255, c[1] / 255, c[2] / 255}
+	}
+	return floatColor(c)
+}
+
+func (m *Mesh) loadMTL(file, directory string, ln int, report *diagnostic.Report) {
+	mtlPath := filepath.Join(directory, file)
+	f, err := os.Open(mtlPath)
+	if err != nil {
+		report.Add("mtl", m.Name, ln, err)
+		return
+	}
+	defer f.Close()
+
+	materials, err := ParseMTL(f, mtlPath, directory, report)
+	if err != nil {
+		report.Add("mtl", m.Name, ln, err)
+		return
+	}
+	for k, v := range materials {
+		m.Materials[k] = v
+	}
+}
+
+// Parse "v", "v/vt", "v//vn", "v/vt/vn" list and triangulate
+func (m *Mesh) parseFace(args []string, triangulation Triangulation) (faces []Face, err error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("face has %d vertices", len(args))
+	}
+
+	type corner struct{ v, vt, vn int }
+	corners := make([]corner, len(args))
+	for i, arg := range args {
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("face index %q", arg)
+		}
+		c := corner{v: -1, vt: -1, vn: -1}
+		if c.v, err = resolveIndex(parts[0], len(m.Vertices), true); err != nil {
+			return nil, fmt.Errorf("face index %q: vertex: %w", arg, err)
+		}
+		if len(parts) > 1 {
+			if c.vt, err = resolveIndex(parts[1], len(m.UVs), false); err != nil {
+				return nil, fmt.Errorf("face index %q: texture: %w", arg, err)
+			}
+		}
+		if len(parts) > 2 {
+			if c.vn, err = resolveIndex(parts[2], len(m.Normals), false); err != nil {
+				return nil, fmt.Errorf("face index %q: normal: %w", arg, err)
+			}
+		}
+		corners[i] = c
+	}
+
+	polygon := make([][3]float64, len(corners))
+	for i, c := range corners {
+		polygon[i] = m.Vertices[c.v]
+	}
+	for _, tri := range Triangulate(polygon, triangulation) {
+		var face Face
+		for i, index := range tri {
+			face.Vertex[i] = corners[index].v
+			face.UV[i] = corners[index].vt
+			face.Normal[i] = corners[index].vn
+		}
+		faces = append(faces, face)
+	}
+	return faces, nil
+}
+
+// 1 origin or negative(relative) index to 0 origin index
+//
+// Empty index returns -1 when not required.
+func resolveIndex(s string, length int, required bool) (int, error) {
+	if s == "" {
+		if required {
+			return -1, fmt.Errorf("missing index")
+		}
+		return -1, nil
+	}
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return -1, err
+	}
+	if index < 0 {
+		index = length + index + 1
+	}
+	if index < 1 || index > length {
+		return -1, fmt.Errorf("index %s out of range(1..%d)", s, length)
+	}
+	return index - 1, nil
+}
+
+// Parse least..most floats, missing values are 0
+func parseFloats(args []string, least, most int) (v [3]float64, err error) {
+	if len(args) < least {
+		return v, fmt.Errorf("need %d values, got %d", least, len(args))
+	}
+	for i := 0; i < most && i < len(args); i++ {
+		v[i], err = strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			return v, err
+		}
+	}
+	return
+}
+
+// Multiply all vertices
+func (m *Mesh) Scale(scale float64) {
+	for i := range m.Vertices {
+		m.Vertices[i][0] *= scale
+		m.Vertices[i][1] *= scale
+		m.Vertices[i][2] *= scale
+	}
+}
+
+// Logical line reader: CRLF, "#" comments and "\" continuation
+type lineReader struct {
+	scanner *bufio.Scanner
+	line    int // Start line of current logical line
+	next    int
+	text    string
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &lineReader{scanner: scanner}
+}
+
+func (l *lineReader) Scan() bool {
+	var builder strings.Builder
+	l.line = l.next + 1
+	scanned := false
+	for l.scanner.Scan() {
+		scanned = true
+		l.next++
+		text := strings.TrimRight(l.scanner.Text(), "\r")
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		trimmed := strings.TrimRight(text, " \t")
+		if strings.HasSuffix(trimmed, "\\") {
+			builder.WriteString(trimmed[:len(trimmed)-1])
+			builder.WriteString(" ")
+			continue
+		}
+		builder.WriteString(text)
+		break
+	}
+	l.text = builder.String()
+	return scanned
+}
+
+// 1 origin
+func (l *lineReader) Line() int {
+	return l.line
+}
+
+func (l *lineReader) Fields() []string {
+	return strings.Fields(l.text)
+}
+
+func (l *lineReader) Err() error {
+	return l.scanner.Err()
+}
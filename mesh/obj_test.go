@@ -0,0 +1,137 @@
+package mesh
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func parseSample(t *testing.T, src string, triangulation Triangulation) (*Mesh, *diagnostic.Report) {
+	t.Helper()
+	report := diagnostic.NewReport(nil)
+	m, err := ParseOBJ(strings.NewReader(src), "sample.obj", ParseOptions{Directory: "testdata", Triangulation: triangulation}, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, report
+}
+
+func readSample(t *testing.T) string {
+	t.Helper()
+	b, err := os.ReadFile("testdata/sample.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// Sum of unsigned triangle areas on the XY plane
+func faceArea(m *Mesh, faces []Face) (area float64) {
+	for _, f := range faces {
+		a, b, c := m.Vertices[f.Vertex[0]], m.Vertices[f.Vertex[1]], m.Vertices[f.Vertex[2]]
+		area += math.Abs((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])) / 2
+	}
+	return
+}
+
+func TestParseOBJTriangulation(t *testing.T) {
+	src := readSample(t)
+
+	// Concave L shape of area 3, the fan from vertex 1 covers outside of it
+	tests := []struct {
+		triangulation Triangulation
+		area          float64
+	}{
+		{EarClipping, 3},
+		{Fan, 4},
+	}
+	for _, tt := range tests {
+		m, _ := parseSample(t, src, tt.triangulation)
+		var hexagon []Face
+		for _, f := range m.Faces {
+			if f.Line == 16 {
+				hexagon = append(hexagon, f)
+			}
+		}
+		if len(hexagon) != 4 {
+			t.Fatalf("triangulation %d: %d triangles, want 4", tt.triangulation, len(hexagon))
+		}
+		if area := faceArea(m, hexagon); math.Abs(area-tt.area) > 1e-9 {
+			t.Errorf("triangulation %d: area %f, want %f", tt.triangulation, area, tt.area)
+		}
+	}
+}
+
+func TestParseOBJFaces(t *testing.T) {
+	m, report := parseSample(t, readSample(t), EarClipping)
+	if len(m.Vertices) != 6 || len(m.UVs) != 2 || len(m.Normals) != 1 {
+		t.Fatalf("v %d vt %d vn %d, want 6 2 1", len(m.Vertices), len(m.UVs), len(m.Normals))
+	}
+	if len(m.Faces) != 6 {
+		t.Fatalf("%d faces, want 6", len(m.Faces))
+	}
+
+	hexagon := m.Faces[0]
+	if hexagon.Object != "first" || hexagon.Group != "walls" || hexagon.Smooth != 1 || hexagon.Material != "red" {
+		t.Errorf("hexagon o %q g %q s %d material %q", hexagon.Object, hexagon.Group, hexagon.Smooth, hexagon.Material)
+	}
+	if hexagon.HasUV() || hexagon.Normal != [3]int{-1, -1, -1} {
+		t.Errorf("hexagon without vt/vn: uv %v normal %v", hexagon.UV, hexagon.Normal)
+	}
+
+	// Negative indices and v//vn
+	relative := m.Faces[4]
+	if relative.Line != 20 || relative.Vertex != [3]int{0, 1, 2} || relative.HasUV() || relative.Normal != [3]int{0, 0, 0} {
+		t.Errorf("relative face: %+v", relative)
+	}
+	if relative.Object != "second" || relative.Group != "floor" || relative.Smooth != 0 {
+		t.Errorf("relative face o %q g %q s %d", relative.Object, relative.Group, relative.Smooth)
+	}
+
+	// "\" continuation keeps the start line
+	continued := m.Faces[5]
+	if continued.Line != 21 || continued.UV != [3]int{0, 1, 0} {
+		t.Errorf("continued face: %+v", continued)
+	}
+
+	// Skipped faces report their lines
+	items := report.Items()
+	if len(items) != 2 || items[0].Line != 23 || items[1].Line != 24 {
+		t.Fatalf("reports %v, want lines 23 and 24", items)
+	}
+	for _, d := range items {
+		if d.Stage != "object" || !strings.Contains(d.Err.Error(), "skip face") {
+			t.Errorf("report %s", d)
+		}
+	}
+
+	material, ok := m.Materials["red"]
+	if !ok {
+		t.Fatal("material red is not loaded")
+	}
+	if c, _ := material.Color(); c != (palette.Color{R: 255}) {
+		t.Errorf("material color %v, want red", c)
+	}
+}
+
+func TestParseOBJCRLF(t *testing.T) {
+	src := readSample(t)
+	lf, lfReport := parseSample(t, src, EarClipping)
+	crlf, crlfReport := parseSample(t, strings.ReplaceAll(src, "\n", "\r\n"), EarClipping)
+
+	if len(lf.Faces) != len(crlf.Faces) || lfReport.Len() != crlfReport.Len() {
+		t.Fatalf("CRLF faces %d reports %d, LF faces %d reports %d", len(crlf.Faces), crlfReport.Len(), len(lf.Faces), lfReport.Len())
+	}
+	for i := range lf.Faces {
+		if lf.Faces[i] != crlf.Faces[i] {
+			t.Errorf("face %d: CRLF %+v, LF %+v", i, crlf.Faces[i], lf.Faces[i])
+		}
+	}
+	if _, ok := crlf.Materials["red"]; !ok {
+		t.Error("CRLF material red is not loaded")
+	}
+}
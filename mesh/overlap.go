@@ -0,0 +1,125 @@
+package mesh
+
+import (
+	"math"
+)
+
+type vec3 = [3]float64
+
+func sub(a, b vec3) vec3 {
+	return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func dot(a, b vec3) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// Triangle/axis aligned box overlap by separating axis theorem(Akenine-Möller)
+func triangleBoxOverlap(center vec3, half float64, a, b, c vec3) bool {
+	// Move box to origin
+	v0, v1, v2 := sub(a, center), sub(b, center), sub(c, center)
+	edges := [3]vec3{sub(v1, v0), sub(v2, v1), sub(v0, v2)}
+
+	// 9 axes: box axes × triangle edges
+	for _, e := range edges {
+		for axis := 0; axis < 3; axis++ {
+			var n vec3
+			n[(axis+1)%3] = -e[(axis+2)%3]
+			n[(axis+2)%3] = e[(axis+1)%3]
+			p0, p1, p2 := dot(n, v0), dot(n, v1), dot(n, v2)
+			r := half * (math.Abs(n[0]) + math.Abs(n[1]) + math.Abs(n[2]))
+			if math.Min(p0, math.Min(p1, p2)) > r || math.Max(p0, math.Max(p1, p2)) < -r {
+				return false
+			}
+		}
+	}
+
+	// 3 axes: box face normals
+	for axis := 0; axis < 3; axis++ {
+		if math.Min(v0[axis], math.Min(v1[axis], v2[axis])) > half || math.Max(v0[axis], math.Max(v1[axis], v2[axis])) < -half {
+			return false
+		}
+	}
+
+	// 1 axis: triangle normal
+	normal := cross(edges[0], edges[1])
+	d := dot(normal, v0)
+	r := half * (math.Abs(normal[0]) + math.Abs(normal[1]) + math.Abs(normal[2]))
+	return math.Abs(d) <= r
+}
+
+// Barycentric weights of the closest point on triangle to p
+func closestBarycentric(p, a, b, c vec3) (la, lb, lc float64) {
+	ab, ac, ap := sub(b, a), sub(c, a), sub(p, a)
+	d1, d2 := dot(ab, ap), dot(ac, ap)
+	if d1 <= 0 && d2 <= 0 {
+		return 1, 0, 0
+	}
+	bp := sub(p, b)
+	d3, d4 := dot(ab, bp), dot(ac, bp)
+	if d3 >= 0 && d4 <= d3 {
+		return 0, 1, 0
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		return 1 - v, v, 0
+	}
+	cp := sub(p, c)
+	d5, d6 := dot(ab, cp), dot(ac, cp)
+	if d6 >= 0 && d5 <= d6 {
+		return 0, 0, 1
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		return 1 - w, 0, w
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		return 0, 1 - w, w
+	}
+	denom := 1 / (va + vb + vc)
+	lb = vb * denom
+	lc = vc * denom
+	return 1 - lb - lc, lb, lc
+}
+
+// Area of triangle clipped by box(Sutherland-Hodgman)
+func clippedArea(center vec3, half float64, a, b, c vec3) float64 {
+	polygon := []vec3{a, b, c}
+	for axis := 0; axis < 3; axis++ {
+		for _, side := range [2]float64{-1, 1} {
+			limit := center[axis] + side*half
+			// Inside when side*(p[axis]-limit) <= 0
+			var clipped []vec3
+			for i, p := range polygon {
+				q := polygon[(i+1)%len(polygon)]
+				dp, dq := side*(p[axis]-limit), side*(q[axis]-limit)
+				if dp <= 0 {
+					clipped = append(clipped, p)
+				}
+				if (dp < 0 && dq > 0) || (dp > 0 && dq < 0) {
+					t := dp / (dp - dq)
+					clipped = append(clipped, vec3{p[0] + (q[0]-p[0])*t, p[1] + (q[1]-p[1])*t, p[2] + (q[2]-p[2])*t})
+				}
+			}
+			polygon = clipped
+			if len(polygon) < 3 {
+				return 0
+			}
+		}
+	}
+
+	var sum vec3
+	for i := 1; i+1 < len(polygon); i++ {
+		n := cross(sub(polygon[i], polygon[0]), sub(polygon[i+1], polygon[0]))
+		sum = vec3{sum[0] + n[0], sum[1] + n[1], sum[2] + n[2]}
+	}
+	return math.Sqrt(dot(sum, sum)) / 2
+}
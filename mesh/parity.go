@@ -0,0 +1,58 @@
+package mesh
+
+import (
+	"math"
+	"slices"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Inside test by ray casting along +X, crossing count of faces is odd inside.
+//
+// Mesh must be watertight.
+func (m *Mesh) ParityInterior(spacing float64) func(p voxel.Pos) bool {
+	// Rays are shifted slightly so they don't hit edges and vertices exactly
+	const jitter = 1e-4
+
+	type row struct{ y, z int }
+	crossings := map[row][]float64{}
+
+	for _, face := range m.Faces {
+		a, b, c := m.Vertices[face.Vertex[0]], m.Vertices[face.Vertex[1]], m.Vertices[face.Vertex[2]]
+
+		minY := int(math.Ceil(math.Min(a[1], math.Min(b[1], c[1]))/spacing - jitter))
+		maxY := int(math.Floor(math.Max(a[1], math.Max(b[1], c[1]))/spacing - jitter))
+		minZ := int(math.Ceil(math.Min(a[2], math.Min(b[2], c[2]))/spacing - jitter))
+		maxZ := int(math.Floor(math.Max(a[2], math.Max(b[2], c[2]))/spacing - jitter))
+
+		// Barycentric on YZ plane
+		det := (b[1]-a[1])*(c[2]-a[2]) - (c[1]-a[1])*(b[2]-a[2])
+		if det == 0 {
+			continue
+		}
+		for y := minY; y <= maxY; y++ {
+			for z := minZ; z <= maxZ; z++ {
+				py := (float64(y) + jitter) * spacing
+				pz := (float64(z) + jitter*0.7) * spacing
+				lb := ((py-a[1])*(c[2]-a[2]) - (c[1]-a[1])*(pz-a[2])) / det
+				lc := ((b[1]-a[1])*(pz-a[2]) - (py-a[1])*(b[2]-a[2])) / det
+				la := 1 - lb - lc
+				if la < 0 || lb < 0 || lc < 0 {
+					continue
+				}
+				x := a[0]*la + b[0]*lb + c[0]*lc
+				crossings[row{y, z}] = append(crossings[row{y, z}], x)
+			}
+		}
+	}
+	for k := range crossings {
+		slices.Sort(crossings[k])
+	}
+
+	return func(p voxel.Pos) bool {
+		xs := crossings[row{p.Y, p.Z}]
+		x := float64(p.X) * spacing
+		count, _ := slices.BinarySearch(xs, x)
+		return count%2 == 1
+	}
+}
@@ -0,0 +1,361 @@
+package mesh
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+type plyProperty struct {
+	name      string
+	kind      string // Scalar type, or list item type
+	countKind string // List count type, "": scalar
+}
+
+// Items of one list property, larger counts are broken files
+const plyListLimit = 1 << 16
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// Parse ASCII/binary .ply vertices(x, y, z, red, green, blue) and optional faces
+//
+// Faces use interpolated vertex colors, or face colors.
+func ParsePLY(r io.Reader, name string, triangulation Triangulation, report *diagnostic.Report) (*Mesh, error) {
+	br := bufio.NewReader(r)
+	format, elements, headerLines, err := parsePLYHeader(br)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var (
+		read func(properties []plyProperty) ([][]float64, error)
+		line = func() int { return 0 } // Source line of the last element, 0: binary
+	)
+	switch format {
+	case "ascii":
+		lines := newLineReader(br)
+		lines.next = headerLines
+		read = func(properties []plyProperty) ([][]float64, error) {
+			return readPLYASCII(lines, properties)
+		}
+		line = lines.Line
+	case "binary_little_endian", "binary_big_endian":
+		var order binary.ByteOrder = binary.LittleEndian
+		if format == "binary_big_endian" {
+			order = binary.BigEndian
+		}
+		read = func(properties []plyProperty) ([][]float64, error) {
+			return readPLYBinary(br, order, properties)
+		}
+	default:
+		return nil, fmt.Errorf("%s: format %q is not supported", name, format)
+	}
+
+	m := &Mesh{
+		Name:      name,
+		Materials: map[string]*Material{},
+	}
+	for _, element := range elements {
+		switch element.name {
+		case "vertex":
+			index := map[string]int{}
+			for i, p := range element.properties {
+				index[p.name] = i
+			}
+			x, y, z, okPosition := lookup3(index, "x", "y", "z")
+			if !okPosition {
+				return nil, fmt.Errorf("%s: vertex has no x/y/z", name)
+			}
+			cr, cg, cb, okColor := lookup3(index, "red", "green", "blue")
+			if !okColor {
+				cr, cg, cb, okColor = lookup3(index, "diffuse_red", "diffuse_green", "diffuse_blue")
+			}
+			for i := 0; i < element.count; i++ {
+				values, err := read(element.properties)
+				if err != nil {
+					return nil, fmt.Errorf("%s: vertex %d: %w", name, i, err)
+				}
+				m.Vertices = append(m.Vertices, [3]float64{values[x][0], values[y][0], values[z][0]})
+				if okColor {
+					m.Colors = append(m.Colors, palette.Color{
+						R: plyColor(values[cr][0], element.properties[cr].kind),
+						G: plyColor(values[cg][0], element.properties[cg].kind),
+						B: plyColor(values[cb][0], element.properties[cb].kind),
+					})
+				}
+			}
+
+		case "face":
+			list := -1
+			index := map[string]int{}
+			for i, p := range element.properties {
+				index[p.name] = i
+				if p.countKind != "" && (p.name == "vertex_indices" || p.name == "vertex_index") {
+					list = i
+				}
+			}
+			if list < 0 {
+				return nil, fmt.Errorf("%s: face has no vertex_indices", name)
+			}
+			cr, cg, cb, okColor := lookup3(index, "red", "green", "blue")
+			for i := 0; i < element.count; i++ {
+				values, err := read(element.properties)
+				if err != nil {
+					return nil, fmt.Errorf("%s: face %d: %w", name, i, err)
+				}
+				var color *palette.Color
+				if okColor {
+					color = &palette.Color{
+						R: plyColor(values[cr][0], element.properties[cr].kind),
+						G: plyColor(values[cg][0], element.properties[cg].kind),
+						B: plyColor(values[cb][0], element.properties[cb].kind),
+					}
+				}
+				if err := m.addPLYFace(values[list], color, triangulation, line(), i); err != nil {
+					report.Add("object", name, line(), fmt.Errorf("skip face %d: %w", i, err))
+				}
+			}
+
+		default:
+			// Unused element
+			for i := 0; i < element.count; i++ {
+				if _, err := read(element.properties); err != nil {
+					return nil, fmt.Errorf("%s: %s %d: %w", name, element.name, i, err)
+				}
+			}
+		}
+	}
+
+	if len(m.Vertices) == 0 {
+		return nil, fmt.Errorf("%s: no vertex found", name)
+	}
+	return m, nil
+}
+
+func parsePLYHeader(br *bufio.Reader) (format string, elements []plyElement, lines int, err error) {
+	magic, err := br.ReadString('\n')
+	if err != nil || strings.TrimSpace(magic) != "ply" {
+		return "", nil, 0, fmt.Errorf("not a .ply file")
+	}
+	lines = 1
+	for {
+		line, err := br.ReadString('\n')
+		lines++
+		if err != nil {
+			return "", nil, 0, fmt.Errorf("broken header: %w", err)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return "", nil, 0, fmt.Errorf("broken format line")
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, 0, fmt.Errorf("broken element line: %q", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return "", nil, 0, fmt.Errorf("broken element count: %q", fields[2])
+			}
+			elements = append(elements, plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, 0, fmt.Errorf("property without element")
+			}
+			var p plyProperty
+			switch {
+			case len(fields) == 5 && fields[1] == "list":
+				p = plyProperty{name: fields[4], kind: fields[3], countKind: fields[2]}
+			case len(fields) == 3:
+				p = plyProperty{name: fields[2], kind: fields[1]}
+			default:
+				return "", nil, 0, fmt.Errorf("broken property line: %q", strings.TrimSpace(line))
+			}
+			if plySize(p.kind) == 0 || (p.countKind != "" && plySize(p.countKind) == 0) {
+				return "", nil, 0, fmt.Errorf("property %s: unknown type", p.name)
+			}
+			last := &elements[len(elements)-1]
+			last.properties = append(last.properties, p)
+		case "end_header":
+			return format, elements, lines, nil
+		}
+	}
+}
+
+func lookup3(index map[string]int, a, b, c string) (ia, ib, ic int, ok bool) {
+	ia, okA := index[a]
+	ib, okB := index[b]
+	ic, okC := index[c]
+	return ia, ib, ic, okA && okB && okC
+}
+
+// Byte size of scalar type, 0: unknown
+func plySize(kind string) int {
+	switch kind {
+	case "char", "uchar", "int8", "uint8":
+		return 1
+	case "short", "ushort", "int16", "uint16":
+		return 2
+	case "int", "uint", "int32", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
+
+// Integer colors are 0-255, float colors are 0-1
+func plyColor(v float64, kind string) uint8 {
+	switch kind {
+	case "float", "float32", "double", "float64":
+		return uint8(math.Round(clamp01(v) * 255))
+	}
+	return uint8(max(0, min(255, v)))
+}
+
+func readPLYASCII(lines *lineReader, properties []plyProperty) (values [][]float64, err error) {
+	var fields []string
+	for len(fields) == 0 {
+		if !lines.Scan() {
+			if err := lines.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.ErrUnexpectedEOF
+		}
+		fields = lines.Fields()
+	}
+
+	next := func() (float64, error) {
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("line %d: too few values", lines.Line())
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		fields = fields[1:]
+		return v, err
+	}
+	for _, p := range properties {
+		count := 1
+		if p.countKind != "" {
+			n, err := next()
+			if err != nil {
+				return nil, err
+			}
+			count = int(n)
+			if count < 0 || count > len(fields) {
+				return nil, fmt.Errorf("line %d: list count %d out of range", lines.Line(), count)
+			}
+		}
+		list := make([]float64, count)
+		for i := range list {
+			if list[i], err = next(); err != nil {
+				return nil, err
+			}
+		}
+		values = append(values, list)
+	}
+	return values, nil
+}
+
+func readPLYBinary(r io.Reader, order binary.ByteOrder, properties []plyProperty) (values [][]float64, err error) {
+	var buf [8]byte
+	scalar := func(kind string) (float64, error) {
+		b := buf[:plySize(kind)]
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+		switch kind {
+		case "char", "int8":
+			return float64(int8(b[0])), nil
+		case "uchar", "uint8":
+			return float64(b[0]), nil
+		case "short", "int16":
+			return float64(int16(order.Uint16(b))), nil
+		case "ushort", "uint16":
+			return float64(order.Uint16(b)), nil
+		case "int", "int32":
+			return float64(int32(order.Uint32(b))), nil
+		case "uint", "uint32":
+			return float64(order.Uint32(b)), nil
+		case "float", "float32":
+			return float64(math.Float32frombits(order.Uint32(b))), nil
+		default:
+			return math.Float64frombits(order.Uint64(b)), nil
+		}
+	}
+	for _, p := range properties {
+		count := 1
+		if p.countKind != "" {
+			n, err := scalar(p.countKind)
+			if err != nil {
+				return nil, err
+			}
+			count = int(n)
+			if count < 0 || count > plyListLimit {
+				return nil, fmt.Errorf("list count %d out of range", count)
+			}
+		}
+		list := make([]float64, count)
+		for i := range list {
+			if list[i], err = scalar(p.kind); err != nil {
+				return nil, err
+			}
+		}
+		values = append(values, list)
+	}
+	return values, nil
+}
+
+// line: ASCII source line, 0: binary, index: face element index
+func (m *Mesh) addPLYFace(indices []float64, color *palette.Color, triangulation Triangulation, line, index int) error {
+	if len(indices) < 3 {
+		return fmt.Errorf("face needs 3 vertices, got %d", len(indices))
+	}
+	polygon := make([][3]float64, len(indices))
+	vertex := make([]int, len(indices))
+	for i, f := range indices {
+		v := int(f)
+		if v < 0 || v >= len(m.Vertices) {
+			return fmt.Errorf("vertex index %d out of range", v)
+		}
+		vertex[i] = v
+		polygon[i] = m.Vertices[v]
+	}
+	group := ""
+	if line == 0 {
+		// Binary faces have no source line
+		group = fmt.Sprintf("face %d", index)
+	}
+
+	material := ""
+	if color != nil {
+		material = m.colorMaterial([3]float64{float64(color.R) / 255, float64(color.G) / 255, float64(color.B) / 255})
+	}
+
+	for _, tri := range Triangulate(polygon, triangulation) {
+		m.Faces = append(m.Faces, Face{
+			Line:     line,
+			Group:    group,
+			Vertex:   [3]int{vertex[tri[0]], vertex[tri[1]], vertex[tri[2]]},
+			UV:       [3]int{-1, -1, -1},
+			Normal:   [3]int{-1, -1, -1},
+			Material: material,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+const plyHeader = `ply
+format ascii 1.0
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+end_header
+`
+
+func TestParsePLYASCII(t *testing.T) {
+	src := plyHeader + `0 0 0 255 0 0
+1 0 0 0 255 0
+1 1 0 0 0 255
+0 1 0 255 255 255
+4 0 1 2 3
+`
+	m, err := ParsePLY(strings.NewReader(src), "quad.ply", EarClipping, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Vertices) != 4 || len(m.Faces) != 2 {
+		t.Fatalf("vertices %d faces %d, want 4 2", len(m.Vertices), len(m.Faces))
+	}
+	if m.Colors[1] != (palette.Color{G: 255}) {
+		t.Errorf("color %v, want green", m.Colors[1])
+	}
+	// File line after the 12 header lines and 4 vertices
+	if f := m.Faces[0]; f.Line != 17 || f.Group != "" {
+		t.Errorf("face line %d group %q, want 17 without group", f.Line, f.Group)
+	}
+}
+
+func TestParsePLYBinaryFace(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("ply\nformat binary_little_endian 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n")
+	b.WriteString("element face 1\nproperty list uchar int vertex_indices\nend_header\n")
+	binary.Write(&b, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	b.WriteByte(3)
+	binary.Write(&b, binary.LittleEndian, []int32{0, 1, 2})
+
+	m, err := ParsePLY(&b, "tri.ply", EarClipping, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Binary faces are named, not lines
+	if len(m.Faces) != 1 || m.Faces[0].Line != 0 || m.Faces[0].Group != "face 0" {
+		t.Errorf("faces %+v, want one face 0 without line", m.Faces)
+	}
+}
+
+func TestParsePLYBrokenListCount(t *testing.T) {
+	for _, face := range []string{"-1 0 1 2", "200 0 1 2"} {
+		src := plyHeader + "0 0 0 0 0 0\n1 0 0 0 0 0\n1 1 0 0 0 0\n0 1 0 0 0 0\n" + face + "\n"
+		_, err := ParsePLY(strings.NewReader(src), "broken.ply", EarClipping, nil)
+		if err == nil || !strings.Contains(err.Error(), "list count") {
+			t.Errorf("face %q: error %v, want list count error", face, err)
+		}
+	}
+}
+
+func TestParsePLYBinaryHugeListCount(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("ply\nformat binary_little_endian 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n")
+	b.WriteString("element face 1\nproperty list uint int vertex_indices\nend_header\n")
+	binary.Write(&b, binary.LittleEndian, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	binary.Write(&b, binary.LittleEndian, uint32(0xFFFFFFF0))
+
+	_, err := ParsePLY(&b, "huge.ply", EarClipping, nil)
+	if err == nil || !strings.Contains(err.Error(), "list count") {
+		t.Fatalf("error %v, want list count error", err)
+	}
+}
+
+func TestParsePLYFaceOutOfRange(t *testing.T) {
+	src := plyHeader + "0 0 0 0 0 0\n1 0 0 0 0 0\n1 1 0 0 0 0\n0 1 0 0 0 0\n3 0 1 9\n"
+	report := diagnostic.NewReport(nil)
+	m, err := ParsePLY(strings.NewReader(src), "range.ply", EarClipping, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 0 || report.Len() != 1 || report.Items()[0].Line != 17 {
+		t.Errorf("faces %d reports %v, want 0 faces and a report at line 17", len(m.Faces), report.Items())
+	}
+}
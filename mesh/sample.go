@@ -0,0 +1,155 @@
+package mesh
+
+import (
+	"math"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Texture filtering of a voxel sample
+type TextureFilter int
+
+const (
+	Nearest     TextureFilter = iota // Texel at the sample point
+	Bilinear                         // Blend 4 texels around the sample point
+	AreaAverage                      // Average texels covered by the voxel footprint(box filter)
+)
+
+// Texture coordinates outside 0..1
+type WrapMode int
+
+const (
+	Repeat WrapMode = iota // Tile
+	Clamp                  // Edge texel
+	Mirror                 // Tile flipped every repeat
+)
+
+// Most texels read per axis by AreaAverage, larger footprints are strided
+const maxAreaSamples = 64
+
+type Sampler struct {
+	Filter    TextureFilter
+	Wrap      WrapMode
+	UVYAxisUp bool // v=0 is the image bottom
+}
+
+// Texel index inside 0..n-1
+func (s Sampler) wrap(i, n int) int {
+	switch s.Wrap {
+	case Clamp:
+		return max(0, min(n-1, i))
+	case Mirror:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i
+	default:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+}
+
+// Nearest texel index of uv in width x height image
+func (s Sampler) nearest(u, v float64, width, height int) (x, y int) {
+	// Image position mapping
+	//  Golang:   | Obj:
+	//   0 - X+   |  Y+
+	//   |        |  |
+	//   Y+       |  0 - X+
+	x = int(math.Floor(u * float64(width)))
+	y = int(math.Floor(v * float64(height)))
+	if s.UVYAxisUp {
+		y = height - 1 - y
+	}
+	return s.wrap(x, width), s.wrap(y, height)
+}
+
+// Continuous image position of uv, texel centers are at +0.5
+func (s Sampler) position(u, v float64, width, height int) (x, y float64) {
+	x, y = u*float64(width), v*float64(height)
+	if s.UVYAxisUp {
+		y = float64(height) - y
+	}
+	return
+}
+
+// Texel color at uv
+//
+// footprint: voxel size in uv units, used by AreaAverage.
+func (t Texture) Sample(u, v, footprint float64, s Sampler) palette.Color {
+	width, height := len(t), len(t[0])
+	switch s.Filter {
+	case Bilinear:
+		x, y := s.position(u, v, width, height)
+		x, y = x-0.5, y-0.5
+		x0, y0 := math.Floor(x), math.Floor(y)
+		fx, fy := x-x0, y-y0
+		var sum [3]float64
+		for _, corner := range [4][3]float64{{0, 0, (1 - fx) * (1 - fy)}, {1, 0, fx * (1 - fy)}, {0, 1, (1 - fx) * fy}, {1, 1, fx * fy}} {
+			c := t[s.wrap(int(x0+corner[0]), width)][s.wrap(int(y0+corner[1]), height)]
+			sum[0] += float64(c.R) * corner[2]
+			sum[1] += float64(c.G) * corner[2]
+			sum[2] += float64(c.B) * corner[2]
+		}
+		return roundColor(sum, 1)
+
+	case AreaAverage:
+		x, y := s.position(u, v, width, height)
+		hx, hy := footprint*float64(width)/2, footprint*float64(height)/2
+		if hx <= 0.5 && hy <= 0.5 {
+			// Footprint within a texel
+			break
+		}
+		return t.boxAverage(x-hx, y-hy, x+hx, y+hy, s)
+	}
+
+	x, y := s.nearest(u, v, width, height)
+	return t[x][y]
+}
+
+// Coverage weighted average of texels in image rectangle
+func (t Texture) boxAverage(x0, y0, x1, y1 float64, s Sampler) palette.Color {
+	width, height := len(t), len(t[0])
+	fromX, toX := int(math.Floor(x0)), int(math.Ceil(x1))-1
+	fromY, toY := int(math.Floor(y0)), int(math.Ceil(y1))-1
+	strideX := max(1, (toX-fromX+1+maxAreaSamples-1)/maxAreaSamples)
+	strideY := max(1, (toY-fromY+1+maxAreaSamples-1)/maxAreaSamples)
+
+	var sum [3]float64
+	var total float64
+	for ix := fromX; ix <= toX; ix += strideX {
+		wx := math.Min(x1, float64(ix+1)) - math.Max(x0, float64(ix))
+		for iy := fromY; iy <= toY; iy += strideY {
+			wy := math.Min(y1, float64(iy+1)) - math.Max(y0, float64(iy))
+			w := wx * wy
+			if w <= 0 {
+				continue
+			}
+			c := t[s.wrap(ix, width)][s.wrap(iy, height)]
+			sum[0] += float64(c.R) * w
+			sum[1] += float64(c.G) * w
+			sum[2] += float64(c.B) * w
+			total += w
+		}
+	}
+	if total == 0 {
+		x, y := s.wrap(int(math.Floor((x0+x1)/2)), width), s.wrap(int(math.Floor((y0+y1)/2)), height)
+		return t[x][y]
+	}
+	return roundColor(sum, total)
+}
+
+func roundColor(sum [3]float64, total float64) palette.Color {
+	return palette.Color{
+		R: uint8(math.Round(math.Max(0, math.Min(255, sum[0]/total)))),
+		G: uint8(math.Round(math.Max(0, math.Min(255, sum[1]/total)))),
+		B: uint8(math.Round(math.Max(0, math.Min(255, sum[2]/total)))),
+	}
+}
@@ -0,0 +1,180 @@
+package mesh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+)
+
+// Parse binary or ASCII .stl
+//
+// Binary facet colors(VisCAM/SolidView and Materialise Magics) become "#rrggbb" materials.
+func ParseSTL(r io.Reader, name string, report *diagnostic.Report) (*Mesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mesh{
+		Name:      name,
+		Materials: map[string]*Material{},
+	}
+	if isBinarySTL(data) {
+		err = m.parseBinarySTL(data)
+	} else {
+		err = m.parseASCIISTL(data, report)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+func isBinarySTL(data []byte) bool {
+	if len(data) < 84 {
+		return false
+	}
+	count := binary.LittleEndian.Uint32(data[80:84])
+	if uint64(len(data)) == 84+50*uint64(count) {
+		return true
+	}
+	return !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid"))
+}
+
+func (m *Mesh) parseBinarySTL(data []byte) error {
+	if len(data) < 84 {
+		return fmt.Errorf("binary stl too short")
+	}
+	header := data[:80]
+	count := int(binary.LittleEndian.Uint32(data[80:84]))
+	if len(data) < 84+50*count {
+		return fmt.Errorf("binary stl has %d facets but %d bytes", count, len(data))
+	}
+	if count == 0 {
+		return fmt.Errorf("no facet found")
+	}
+
+	// Materialise Magics writes "COLOR=" and default RGBA in header
+	materialise := false
+	var defaultColor [3]float64
+	hasDefault := false
+	if i := bytes.Index(header, []byte("COLOR=")); i >= 0 && i+10 <= len(header) {
+		materialise = true
+		c := header[i+6 : i+10]
+		defaultColor = [3]float64{float64(c[0]) / 255, float64(c[1]) / 255, float64(c[2]) / 255}
+		hasDefault = true
+	}
+
+	for i := 0; i < count; i++ {
+		facet := data[84+50*i : 84+50*(i+1)]
+		var face Face
+		// Binary facets have no source line
+		face.Group = fmt.Sprintf("facet %d", i+1)
+		face.UV = [3]int{-1, -1, -1}
+		face.Normal = [3]int{-1, -1, -1}
+		for corner := 0; corner < 3; corner++ {
+			var v [3]float64
+			for axis := 0; axis < 3; axis++ {
+				offset := 12 + corner*12 + axis*4
+				v[axis] = float64(math.Float32frombits(binary.LittleEndian.Uint32(facet[offset : offset+4])))
+			}
+			face.Vertex[corner] = len(m.Vertices)
+			m.Vertices = append(m.Vertices, v)
+		}
+
+		attribute := binary.LittleEndian.Uint16(facet[48:50])
+		var color [3]float64
+		hasColor := false
+		if materialise {
+			// bit 15 = 0: own color, R 0-4, G 5-9, B 10-14
+			if attribute&0x8000 == 0 {
+				color = [3]float64{float64(attribute&0x1f) / 31, float64(attribute>>5&0x1f) / 31, float64(attribute>>10&0x1f) / 31}
+			} else {
+				color = defaultColor
+			}
+			hasColor = attribute&0x8000 == 0 || hasDefault
+		} else if attribute&0x8000 != 0 {
+			// VisCAM/SolidView, bit 15 = 1: valid, B 0-4, G 5-9, R 10-14
+			color = [3]float64{float64(attribute>>10&0x1f) / 31, float64(attribute>>5&0x1f) / 31, float64(attribute&0x1f) / 31}
+			hasColor = true
+		}
+		if hasColor {
+			face.Material = m.colorMaterial(color)
+		}
+		m.Faces = append(m.Faces, face)
+	}
+	return nil
+}
+
+// Material of solid color, shared by same colors
+func (m *Mesh) colorMaterial(color [3]float64) string {
+	c := floatColor(color)
+	name := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	if _, ok := m.Materials[name]; !ok {
+		m.Materials[name] = &Material{Name: name, Diffuse: color, HasDiffuse: true}
+	}
+	return name
+}
+
+func (m *Mesh) parseASCIISTL(data []byte, report *diagnostic.Report) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		ln       int
+		corners  []int
+		faceLine int
+	)
+	for scanner.Scan() {
+		ln++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "facet":
+			corners = corners[:0]
+			faceLine = ln
+		case "vertex":
+			v, err := parseFloats(fields[1:], 3, 3)
+			if err != nil {
+				report.Add("object", m.Name, ln, fmt.Errorf("vertex: %w", err))
+				continue
+			}
+			corners = append(corners, len(m.Vertices))
+			m.Vertices = append(m.Vertices, v)
+		case "endfacet":
+			if len(corners) < 3 {
+				report.Add("object", m.Name, faceLine, fmt.Errorf("skip facet: %d vertices", len(corners)))
+				continue
+			}
+			// Some exporters write polygons
+			polygon := make([][3]float64, len(corners))
+			for i, index := range corners {
+				polygon[i] = m.Vertices[index]
+			}
+			for _, tri := range Triangulate(polygon, EarClipping) {
+				m.Faces = append(m.Faces, Face{
+					Line:   faceLine,
+					Vertex: [3]int{corners[tri[0]], corners[tri[1]], corners[tri[2]]},
+					UV:     [3]int{-1, -1, -1},
+					Normal: [3]int{-1, -1, -1},
+				})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", ln, err)
+	}
+	if len(m.Faces) == 0 {
+		return fmt.Errorf("no facet found")
+	}
+	return nil
+}
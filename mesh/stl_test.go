@@ -0,0 +1,96 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+)
+
+func binarySTL(header string, attributes ...uint16) []byte {
+	var b bytes.Buffer
+	h := make([]byte, 80)
+	copy(h, header)
+	b.Write(h)
+	binary.Write(&b, binary.LittleEndian, uint32(len(attributes)))
+	for _, attribute := range attributes {
+		binary.Write(&b, binary.LittleEndian, []float32{0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0})
+		binary.Write(&b, binary.LittleEndian, attribute)
+	}
+	return b.Bytes()
+}
+
+func TestParseSTLBinaryColors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		materials []string
+	}{
+		{"viscam", binarySTL("solid exported", 0x8000|0x1f<<10, 0), []string{"#ff0000", ""}},
+		{"materialise", binarySTL("COLOR=\x00\x00\xff\xff", 0x1f, 0x8000), []string{"#ff0000", "#0000ff"}},
+	}
+	for _, tt := range tests {
+		m, err := ParseSTL(bytes.NewReader(tt.data), tt.name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(m.Faces) != len(tt.materials) {
+			t.Fatalf("%s: %d faces, want %d", tt.name, len(m.Faces), len(tt.materials))
+		}
+		for i, f := range m.Faces {
+			if f.Material != tt.materials[i] {
+				t.Errorf("%s: face %d material %q, want %q", tt.name, i, f.Material, tt.materials[i])
+			}
+			// Binary facets are named, not lines
+			if want := fmt.Sprintf("facet %d", i+1); f.Line != 0 || f.Group != want {
+				t.Errorf("%s: face %d line %d group %q, want 0 %q", tt.name, i, f.Line, f.Group, want)
+			}
+		}
+	}
+}
+
+func TestParseSTLASCII(t *testing.T) {
+	src := `solid sample
+facet normal 0 0 1
+  outer loop
+    vertex 0 0 0
+    vertex 1 0 0
+    vertex 1 1 0
+    vertex 0 1 0
+  endloop
+endfacet
+facet normal 0 0 1
+  outer loop
+    vertex 0 0 0
+    vertex 1 0 0
+  endloop
+endfacet
+endsolid sample
+`
+	report := diagnostic.NewReport(nil)
+	m, err := ParseSTL(strings.NewReader(src), "sample.stl", report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 2 || m.Faces[0].Line != 2 {
+		t.Errorf("%d faces at line %d, want 2 at line 2", len(m.Faces), m.Faces[0].Line)
+	}
+	if items := report.Items(); len(items) != 1 || items[0].Line != 10 {
+		t.Errorf("reports %v, want line 10", items)
+	}
+}
+
+func TestParseSTLEmpty(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"binary": binarySTL("empty"),
+		"ascii":  []byte("solid empty\nendsolid empty\n"),
+	} {
+		_, err := ParseSTL(bytes.NewReader(data), name, nil)
+		if err == nil || !strings.Contains(err.Error(), "no facet found") {
+			t.Errorf("%s: error %v, want no facet found", name, err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package mesh
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Surface voxel connectivity
+type Connectivity int
+
+const (
+	Connectivity6  Connectivity = iota // Every voxel touched by the triangle, face connected and watertight
+	Connectivity26                     // One voxel per column touched along the dominant normal axis, thin and watertight
+)
+
+type SurfaceOptions struct {
+	GridSpacing         float64       // Cubic grid spacing
+	Connectivity        Connectivity  // Surface thickness
+	UVYAxisUp           bool          // Depends on the creation software
+	Filter              TextureFilter // Nearest, Bilinear, AreaAverage
+	Wrap                WrapMode      // Repeat, Clamp, Mirror
+	TintTexture         bool          // Multiply map_Kd texel by Kd, Material.TintAlways textures are always tinted
+	MultiplyVertexColor bool          // Multiply texel by vertex colors, untextured faces always use vertex colors
+	DefaultColor        palette.Color // Material without Kd/Ka/map_Kd or unknown material
+	Area                bool          // Set Sample.Area(LargestArea)
+}
+
+// Sample face surface into grid voxels, block id is set only by Material.BlockID
+//
+// Each voxel overlapping the face is visited once, transparent texels(Material.Mask) are skipped.
+func (m *Mesh) SampleFace(face Face, opt SurfaceOptions) (samples []voxel.Sample, err error) {
+	material, ok := m.Materials[face.Material]
+	if !ok {
+		material = &Material{Name: face.Material}
+	}
+	useTexture := material.Texture != nil && face.HasUV()
+	solid, ok := material.Color()
+	if !ok {
+		solid = opt.DefaultColor
+	}
+
+	// Get surface polygon top
+	a := m.Vertices[face.Vertex[0]]
+	b := m.Vertices[face.Vertex[1]]
+	c := m.Vertices[face.Vertex[2]]
+	normal := cross(sub(b, a), sub(c, a))
+	if dot(normal, normal) == 0 {
+		return nil, fmt.Errorf("degenerate face")
+	}
+	length := math.Sqrt(dot(normal, normal))
+	unitNormal := [3]float64{normal[0] / length, normal[1] / length, normal[2] / length}
+
+	sampler := Sampler{
+		Filter:    opt.Filter,
+		Wrap:      opt.Wrap,
+		UVYAxisUp: opt.UVYAxisUp && !m.UVTopLeft,
+	}
+	spacing := opt.GridSpacing
+	// Voxel size in uv units, uv area per face area
+	var footprint float64
+	if useTexture {
+		ta, tb, tc := m.UVs[face.UV[0]], m.UVs[face.UV[1]], m.UVs[face.UV[2]]
+		uvArea := math.Abs((tb[0]-ta[0])*(tc[1]-ta[1]) - (tc[0]-ta[0])*(tb[1]-ta[1]))
+		footprint = math.Sqrt(uvArea/length) * spacing
+	}
+	useVertexColor := len(m.Colors) > 0
+	colorAt := func(center vec3) (palette.Color, bool) {
+		if !useTexture && !useVertexColor {
+			return solid, true
+		}
+		la, lb, lc := closestBarycentric(center, a, b, c)
+		var vertexColor palette.Color
+		if useVertexColor {
+			ca, cb, cc := m.Colors[face.Vertex[0]], m.Colors[face.Vertex[1]], m.Colors[face.Vertex[2]]
+			vertexColor = roundColor([3]float64{
+				float64(ca.R)*la + float64(cb.R)*lb + float64(cc.R)*lc,
+				float64(ca.G)*la + float64(cb.G)*lb + float64(cc.G)*lc,
+				float64(ca.B)*la + float64(cb.B)*lb + float64(cc.B)*lc,
+			}, 1)
+			if !useTexture {
+				return vertexColor, true
+			}
+		}
+
+		ta, tb, tc := m.UVs[face.UV[0]], m.UVs[face.UV[1]], m.UVs[face.UV[2]]
+		u := ta[0]*la + tb[0]*lb + tc[0]*lc
+		v := ta[1]*la + tb[1]*lb + tc[1]*lc
+		if !material.Mask.At(u, v, sampler) {
+			return palette.Color{}, false
+		}
+		color := material.Texture.Sample(u, v, footprint, sampler)
+		if opt.TintTexture || material.TintAlways {
+			color = material.Tint(color)
+		}
+		if useVertexColor && opt.MultiplyVertexColor {
+			color = palette.Color{
+				R: uint8((uint16(color.R)*uint16(vertexColor.R) + 127) / 255),
+				G: uint8((uint16(color.G)*uint16(vertexColor.G) + 127) / 255),
+				B: uint8((uint16(color.B)*uint16(vertexColor.B) + 127) / 255),
+			}
+		}
+		return color, true
+	}
+
+	// Grid bounds of the face, voxel p covers (p±0.5)*spacing
+	var lo, hi [3]int
+	for axis := 0; axis < 3; axis++ {
+		lo[axis] = int(math.Round(math.Min(a[axis], math.Min(b[axis], c[axis])) / spacing))
+		hi[axis] = int(math.Round(math.Max(a[axis], math.Max(b[axis], c[axis])) / spacing))
+	}
+
+	// Iterate columns along the dominant normal axis
+	k := 0
+	for axis := 1; axis < 3; axis++ {
+		if math.Abs(normal[axis]) > math.Abs(normal[k]) {
+			k = axis
+		}
+	}
+	u, v := (k+1)%3, (k+2)%3
+	planeD := dot(normal, a)
+	// Position along k on the face plane
+	depth := func(pu, pv float64) float64 {
+		return (planeD - normal[u]*pu - normal[v]*pv) / normal[k]
+	}
+
+	add := func(p [3]int) {
+		center := vec3{float64(p[0]) * spacing, float64(p[1]) * spacing, float64(p[2]) * spacing}
+		color, ok := colorAt(center)
+		if !ok {
+			return
+		}
+		s := voxel.Sample{
+			Position: voxel.Position{X: center[0], Y: center[1], Z: center[2]},
+			Color:    color,
+			BlockID:  material.BlockID,
+			Normal:   unitNormal,
+		}
+		if opt.Area {
+			s.Area = clippedArea(center, spacing/2, a, b, c)
+		}
+		samples = append(samples, s)
+	}
+
+	half := spacing / 2
+	for i := lo[u]; i <= hi[u]; i++ {
+		for j := lo[v]; j <= hi[v]; j++ {
+			cu, cv := float64(i)*spacing, float64(j)*spacing
+
+			if opt.Connectivity == Connectivity26 {
+				// Depth at the column center, or at the closest face point when slivers and edges miss the center
+				var p [3]int
+				p[u], p[v] = i, j
+				switch {
+				case insideProjected(cu, cv, a, b, c, u, v):
+					p[k] = int(math.Round(depth(cu, cv) / spacing))
+				case squareOverlapProjected(cu, cv, half, a, b, c, u, v):
+					var center, pa, pb, pc vec3
+					center[u], center[v] = cu, cv
+					pa[u], pa[v] = a[u], a[v]
+					pb[u], pb[v] = b[u], b[v]
+					pc[u], pc[v] = c[u], c[v]
+					la, lb, lc := closestBarycentric(center, pa, pb, pc)
+					p[k] = int(math.Round((a[k]*la + b[k]*lb + c[k]*lc) / spacing))
+				default:
+					continue
+				}
+				add(p)
+				continue
+			}
+
+			// Plane depth range within the column footprint
+			dMin, dMax := math.Inf(1), math.Inf(-1)
+			for _, corner := range [4][2]float64{{-half, -half}, {-half, half}, {half, -half}, {half, half}} {
+				d := depth(cu+corner[0], cv+corner[1])
+				dMin = math.Min(dMin, d)
+				dMax = math.Max(dMax, d)
+			}
+			from := max(lo[k], int(math.Round(dMin/spacing)))
+			to := min(hi[k], int(math.Round(dMax/spacing)))
+			for d := from; d <= to; d++ {
+				var p [3]int
+				p[u], p[v], p[k] = i, j, d
+				center := vec3{float64(p[0]) * spacing, float64(p[1]) * spacing, float64(p[2]) * spacing}
+				if triangleBoxOverlap(center, half, a, b, c) {
+					add(p)
+				}
+			}
+		}
+	}
+
+	return samples, nil
+}
+
+// Point(pu, pv) inside triangle projected on u-v plane
+func insideProjected(pu, pv float64, a, b, c vec3, u, v int) bool {
+	const epsilon = 1e-9
+	edge := func(p, q vec3) float64 {
+		return (q[u]-p[u])*(pv-p[v]) - (q[v]-p[v])*(pu-p[u])
+	}
+	e0, e1, e2 := edge(a, b), edge(b, c), edge(c, a)
+	return (e0 >= -epsilon && e1 >= -epsilon && e2 >= -epsilon) || (e0 <= epsilon && e1 <= epsilon && e2 <= epsilon)
+}
+
+// Square(pu±half, pv±half) overlaps triangle projected on u-v plane by separating axes, touching is not overlap
+func squareOverlapProjected(pu, pv, half float64, a, b, c vec3, u, v int) bool {
+	const epsilon = 1e-9
+	points := [3][2]float64{{a[u] - pu, a[v] - pv}, {b[u] - pu, b[v] - pv}, {c[u] - pu, c[v] - pv}}
+
+	// 2 axes: square sides
+	for axis := 0; axis < 2; axis++ {
+		lo := math.Min(points[0][axis], math.Min(points[1][axis], points[2][axis]))
+		hi := math.Max(points[0][axis], math.Max(points[1][axis], points[2][axis]))
+		if lo >= half-epsilon || hi <= -half+epsilon {
+			return false
+		}
+	}
+
+	// 3 axes: triangle edge normals
+	for i, p := range points {
+		q, r := points[(i+1)%3], points[(i+2)%3]
+		n := [2]float64{q[1] - p[1], p[0] - q[0]}
+		edge := n[0]*p[0] + n[1]*p[1]
+		// Triangle side of the edge
+		side := n[0]*r[0] + n[1]*r[1] - edge
+		extent := half * (math.Abs(n[0]) + math.Abs(n[1]))
+		if side > 0 && edge-extent >= -epsilon || side < 0 && edge+extent <= epsilon {
+			return false
+		}
+	}
+	return true
+}
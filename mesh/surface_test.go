@@ -0,0 +1,98 @@
+package mesh
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+func sampleAll(t *testing.T, m *Mesh, opt SurfaceOptions) *voxel.Grid {
+	t.Helper()
+	g := voxel.NewGrid(opt.GridSpacing, voxel.FirstWins)
+	for _, face := range m.Faces {
+		samples, err := m.SampleFace(face, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range samples {
+			g.WriteSample(s)
+		}
+	}
+	return g
+}
+
+func TestConnectivity26Sliver(t *testing.T) {
+	// Sliver between column centers x=0 and x=1
+	m := &Mesh{
+		Vertices: [][3]float64{{0.1, -5, 0}, {0.3, 5, 0}, {0.2, 5, 0}},
+		Faces:    []Face{{Vertex: [3]int{0, 1, 2}, UV: [3]int{-1, -1, -1}, Normal: [3]int{-1, -1, -1}}},
+	}
+	g := sampleAll(t, m, SurfaceOptions{GridSpacing: 1, Connectivity: Connectivity26})
+	if g.Len() != 11 {
+		t.Errorf("%d voxels, want 11", g.Len())
+	}
+	for y := -5; y <= 5; y++ {
+		if !g.Has(voxel.Pos{Y: y}) {
+			t.Errorf("column y=%d is missing", y)
+		}
+	}
+}
+
+func TestConnectivity26Watertight(t *testing.T) {
+	// Rotated octahedron, faces have different dominant axes along every edge
+	r := 7.3
+	vertices := [][3]float64{{r, 0, 0}, {-r, 0, 0}, {0, r, 0}, {0, -r, 0}, {0, 0, r}, {0, 0, -r}}
+	rotate := func(p [3]float64) [3]float64 {
+		sa, ca := math.Sincos(0.37)
+		sb, cb := math.Sincos(0.61)
+		x, y, z := p[0]*ca-p[1]*sa, p[0]*sa+p[1]*ca, p[2]
+		return [3]float64{x, y*cb - z*sb, y*sb + z*cb}
+	}
+	m := &Mesh{}
+	for _, v := range vertices {
+		m.Vertices = append(m.Vertices, rotate(v))
+	}
+	for _, x := range []int{0, 1} {
+		for _, y := range []int{2, 3} {
+			for _, z := range []int{4, 5} {
+				m.Faces = append(m.Faces, Face{Vertex: [3]int{x, y, z}, UV: [3]int{-1, -1, -1}, Normal: [3]int{-1, -1, -1}})
+			}
+		}
+	}
+
+	for _, spacing := range []float64{0.3, 0.5, 1, 1.7} {
+		g := sampleAll(t, m, SurfaceOptions{GridSpacing: spacing, Connectivity: Connectivity26})
+		if !g.FloodInterior()(voxel.Pos{}) {
+			t.Errorf("spacing %g: center is reachable from outside", spacing)
+		}
+	}
+}
+
+func TestSampleArea(t *testing.T) {
+	m := &Mesh{
+		Vertices: [][3]float64{{-2, -2, 0}, {2, -2, 0}, {-2, 2, 0}},
+		Faces:    []Face{{Vertex: [3]int{0, 1, 2}, UV: [3]int{-1, -1, -1}, Normal: [3]int{-1, -1, -1}}},
+	}
+	samples, err := m.SampleFace(m.Faces[0], SurfaceOptions{GridSpacing: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range samples {
+		if s.Area != 0 {
+			t.Fatalf("area %f without SurfaceOptions.Area", s.Area)
+		}
+	}
+
+	samples, err = m.SampleFace(m.Faces[0], SurfaceOptions{GridSpacing: 1, Area: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, s := range samples {
+		total += s.Area
+	}
+	if math.Abs(total-8) > 1e-9 {
+		t.Errorf("total area %f, want 8", total)
+	}
+}
@@ -0,0 +1,167 @@
+package mesh
+
+import (
+	"math"
+)
+
+// Model placement after transform
+type Anchor int
+
+const (
+	AnchorNone   Anchor = iota // Keep model coordinates
+	AnchorCenter               // Bounding box center on origin
+	AnchorGround               // X/Z center on origin, min y = 0
+)
+
+// Applied in order: ZUp, Rotation, Mirror, Fit, Anchor, Translate
+type Transform struct {
+	ZUp        bool       // Convert Z-up(Blender/CAD) to Y-up
+	Rotation   [3]float64 // Euler degrees, X→Y→Z
+	Quaternion [4]float64 // w, x, y, z, used instead of Rotation when not zero
+	Mirror     [3]bool    // Flip X/Y/Z
+	FitHeight  int        // Target Y size in blocks(at least 2), 0: off
+	FitWidth   int        // Target max(X, Z) size in blocks(at least 2), 0: off
+	Anchor     Anchor
+	Translate  [3]float64
+}
+
+type matrix [3][3]float64
+
+func (a matrix) mul(b matrix) (m matrix) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return
+}
+
+func (a matrix) apply(v [3]float64) [3]float64 {
+	return [3]float64{
+		a[0][0]*v[0] + a[0][1]*v[1] + a[0][2]*v[2],
+		a[1][0]*v[0] + a[1][1]*v[1] + a[1][2]*v[2],
+		a[2][0]*v[0] + a[2][1]*v[1] + a[2][2]*v[2],
+	}
+}
+
+var identity = matrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+func eulerMatrix(deg [3]float64) matrix {
+	x, y, z := deg[0]*math.Pi/180, deg[1]*math.Pi/180, deg[2]*math.Pi/180
+	rx := matrix{{1, 0, 0}, {0, math.Cos(x), -math.Sin(x)}, {0, math.Sin(x), math.Cos(x)}}
+	ry := matrix{{math.Cos(y), 0, math.Sin(y)}, {0, 1, 0}, {-math.Sin(y), 0, math.Cos(y)}}
+	rz := matrix{{math.Cos(z), -math.Sin(z), 0}, {math.Sin(z), math.Cos(z), 0}, {0, 0, 1}}
+	return rz.mul(ry).mul(rx)
+}
+
+func quaternionMatrix(q [4]float64) matrix {
+	w, x, y, z := q[0], q[1], q[2], q[3]
+	n := math.Sqrt(w*w + x*x + y*y + z*z)
+	w, x, y, z = w/n, x/n, y/n, z/n
+	return matrix{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y)},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x)},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y)},
+	}
+}
+
+// Rotation/mirror part of transform
+func (t Transform) linear() matrix {
+	m := identity
+	if t.ZUp {
+		// (x, y, z) => (x, z, -y)
+		m = matrix{{1, 0, 0}, {0, 0, 1}, {0, -1, 0}}
+	}
+	if t.Quaternion != [4]float64{} {
+		m = quaternionMatrix(t.Quaternion).mul(m)
+	} else if t.Rotation != [3]float64{} {
+		m = eulerMatrix(t.Rotation).mul(m)
+	}
+	mirror := identity
+	for axis, flip := range t.Mirror {
+		if flip {
+			mirror[axis][axis] = -1
+		}
+	}
+	return mirror.mul(m)
+}
+
+// Bounding box of vertices
+func (m *Mesh) Bounds() (min, max [3]float64) {
+	if len(m.Vertices) == 0 {
+		return
+	}
+	min, max = m.Vertices[0], m.Vertices[0]
+	for _, v := range m.Vertices[1:] {
+		for axis := 0; axis < 3; axis++ {
+			min[axis] = math.Min(min[axis], v[axis])
+			max[axis] = math.Max(max[axis], v[axis])
+		}
+	}
+	return
+}
+
+// spacing: grid spacing used to fit size in blocks
+func (m *Mesh) Apply(t Transform, spacing float64) {
+	linear := t.linear()
+	for i := range m.Vertices {
+		m.Vertices[i] = linear.apply(m.Vertices[i])
+	}
+	for i := range m.Normals {
+		m.Normals[i] = linear.apply(m.Normals[i])
+	}
+
+	// Odd mirror count turns faces inside out, keep counter clockwise winding
+	flips := 0
+	for _, flip := range t.Mirror {
+		if flip {
+			flips++
+		}
+	}
+	if flips%2 == 1 {
+		for i := range m.Faces {
+			f := &m.Faces[i]
+			f.Vertex[1], f.Vertex[2] = f.Vertex[2], f.Vertex[1]
+			f.UV[1], f.UV[2] = f.UV[2], f.UV[1]
+			f.Normal[1], f.Normal[2] = f.Normal[2], f.Normal[1]
+		}
+	}
+
+	// Fit to size
+	min, max := m.Bounds()
+	scale := math.Inf(1)
+	// N blocks span (N-1)*spacing between voxel centers, 1 block would collapse the model
+	if height := max[1] - min[1]; t.FitHeight > 0 && height > 0 {
+		scale = math.Min(scale, math.Max(float64(t.FitHeight-1), 1)*spacing/height)
+	}
+	if width := math.Max(max[0]-min[0], max[2]-min[2]); t.FitWidth > 0 && width > 0 {
+		scale = math.Min(scale, math.Max(float64(t.FitWidth-1), 1)*spacing/width)
+	}
+	if !math.IsInf(scale, 1) {
+		m.Scale(scale)
+		for axis := 0; axis < 3; axis++ {
+			min[axis] *= scale
+			max[axis] *= scale
+		}
+	}
+
+	// Anchor and translate
+	offset := t.Translate
+	switch t.Anchor {
+	case AnchorCenter:
+		for axis := 0; axis < 3; axis++ {
+			offset[axis] -= (min[axis] + max[axis]) / 2
+		}
+	case AnchorGround:
+		offset[0] -= (min[0] + max[0]) / 2
+		offset[1] -= min[1]
+		offset[2] -= (min[2] + max[2]) / 2
+	}
+	for i := range m.Vertices {
+		for axis := 0; axis < 3; axis++ {
+			m.Vertices[i][axis] += offset[axis]
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package mesh
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyFit(t *testing.T) {
+	tests := []struct {
+		fit    int
+		height float64
+	}{
+		{1, 1}, // Same as 2, 0 would collapse every vertex
+		{2, 1},
+		{10, 9},
+	}
+	for _, tt := range tests {
+		m := &Mesh{Vertices: [][3]float64{{0, 0, 0}, {1, 4, 1}}}
+		m.Apply(Transform{FitHeight: tt.fit, Anchor: AnchorGround, Translate: [3]float64{0, 5, 0}}, 1)
+		min, max := m.Bounds()
+		if h := max[1] - min[1]; math.Abs(h-tt.height) > 1e-9 {
+			t.Errorf("FitHeight %d: height %f, want %f", tt.fit, h, tt.height)
+		}
+		if min[1] != 5 || math.Abs(min[0]+max[0]) > 1e-9 {
+			t.Errorf("FitHeight %d: bounds %v %v, want ground at translate", tt.fit, min, max)
+		}
+	}
+}
+
+func TestApplyMirrorWinding(t *testing.T) {
+	m := &Mesh{
+		Vertices: [][3]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		Faces:    []Face{{Vertex: [3]int{0, 1, 2}, UV: [3]int{-1, -1, -1}, Normal: [3]int{-1, -1, -1}}},
+	}
+	m.Apply(Transform{Mirror: [3]bool{true, false, false}}, 1)
+	if m.Vertices[1] != [3]float64{-1, 0, 0} || m.Faces[0].Vertex != [3]int{0, 2, 1} {
+		t.Errorf("mirrored vertices %v face %v", m.Vertices, m.Faces[0].Vertex)
+	}
+}
@@ -0,0 +1,110 @@
+package mesh
+
+import (
+	"math"
+)
+
+// Split polygon into triangles of polygon indexes
+func Triangulate(polygon [][3]float64, method Triangulation) [][3]int {
+	if len(polygon) < 3 {
+		return nil
+	}
+	if len(polygon) == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+	if method == EarClipping {
+		if triangles, ok := earClipping(polygon); ok {
+			return triangles
+		}
+	}
+	return fan(len(polygon))
+}
+
+func fan(n int) (triangles [][3]int) {
+	for i := 1; i+1 < n; i++ {
+		triangles = append(triangles, [3]int{0, i, i + 1})
+	}
+	return
+}
+
+// Ear clipping on the polygon plane, false when the polygon is degenerate
+func earClipping(polygon [][3]float64) (triangles [][3]int, ok bool) {
+	// Newell normal
+	var normal [3]float64
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		normal[0] += (a[1] - b[1]) * (a[2] + b[2])
+		normal[1] += (a[2] - b[2]) * (a[0] + b[0])
+		normal[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+
+	// Drop the dominant axis to project into 2D
+	u, v := 1, 2
+	if math.Abs(normal[1]) >= math.Abs(normal[0]) && math.Abs(normal[1]) >= math.Abs(normal[2]) {
+		u, v = 2, 0
+	} else if math.Abs(normal[2]) >= math.Abs(normal[0]) && math.Abs(normal[2]) >= math.Abs(normal[1]) {
+		u, v = 0, 1
+	}
+	dominant := normal[3-u-v]
+	if dominant == 0 {
+		return nil, false
+	}
+
+	points := make([][2]float64, len(polygon))
+	for i, p := range polygon {
+		points[i] = [2]float64{p[u], p[v]}
+	}
+	// Make counter clockwise
+	orientation := 1.0
+	if dominant < 0 {
+		orientation = -1.0
+	}
+
+	cross := func(a, b, c [2]float64) float64 {
+		return ((b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])) * orientation
+	}
+	inside := func(p, a, b, c [2]float64) bool {
+		return cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0
+	}
+
+	remain := make([]int, len(polygon))
+	for i := range remain {
+		remain[i] = i
+	}
+	for len(remain) > 3 {
+		clipped := false
+		for i := range remain {
+			prev := remain[(i+len(remain)-1)%len(remain)]
+			curr := remain[i]
+			next := remain[(i+1)%len(remain)]
+			a, b, c := points[prev], points[curr], points[next]
+			// Reflex vertex
+			if cross(a, b, c) <= 0 {
+				continue
+			}
+			isEar := true
+			for _, other := range remain {
+				if other == prev || other == curr || other == next {
+					continue
+				}
+				if inside(points[other], a, b, c) {
+					isEar = false
+					break
+				}
+			}
+			if !isEar {
+				continue
+			}
+
+			triangles = append(triangles, [3]int{prev, curr, next})
+			remain = append(remain[:i], remain[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, false
+		}
+	}
+	triangles = append(triangles, [3]int{remain[0], remain[1], remain[2]})
+	return triangles, true
+}
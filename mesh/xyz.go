@@ -0,0 +1,103 @@
+package mesh
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Parse .xyz point cloud
+//
+// Columns: "x y z", "x y z r g b" or "x y z intensity r g b", separated by spaces, commas or semicolons.
+// Colors are 0-255 when any color value of the file is over 1, otherwise 0-1.
+// Broken lines are reported and skipped.
+func ParseXYZ(r io.Reader, name string, report *diagnostic.Report) (*Mesh, error) {
+	m := &Mesh{
+		Name:      name,
+		Materials: map[string]*Material{},
+	}
+	separator := func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == ';'
+	}
+
+	var (
+		colored, uncolored int
+		colors             [][3]float64
+		over1              bool // Colors are 0-255
+	)
+	lines := newLineReader(r)
+	for lines.Scan() {
+		fields := strings.FieldsFunc(strings.Join(lines.Fields(), " "), separator)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var color []string
+		switch len(fields) {
+		case 3, 4:
+		case 6:
+			color = fields[3:6]
+		case 7:
+			color = fields[4:7]
+		default:
+			report.Add("object", name, lines.Line(), fmt.Errorf("skip point: %d columns", len(fields)))
+			continue
+		}
+		v, err := parseFloats(fields[:3], 3, 3)
+		if err != nil {
+			report.Add("object", name, lines.Line(), fmt.Errorf("skip point: %w", err))
+			continue
+		}
+		var c [3]float64
+		if color != nil {
+			if c, err = parseFloats(color, 3, 3); err != nil {
+				report.Add("object", name, lines.Line(), fmt.Errorf("skip point: broken color: %w", err))
+				continue
+			}
+			over1 = over1 || c[0] > 1 || c[1] > 1 || c[2] > 1
+		}
+
+		// Colors are kept only when all points have colors
+		if color != nil {
+			colored++
+		} else {
+			uncolored++
+		}
+		m.Vertices = append(m.Vertices, v)
+		colors = append(colors, c)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(m.Vertices) == 0 {
+		return nil, fmt.Errorf("%s: no point found", name)
+	}
+	if uncolored > 0 {
+		if colored > 0 {
+			report.Add("object", name, 0, fmt.Errorf("%d points have no color, colors are ignored", uncolored))
+		}
+		return m, nil
+	}
+
+	m.Colors = make([]palette.Color, len(colors))
+	for i, c := range colors {
+		m.Colors[i] = xyzColor(c, over1)
+	}
+	return m, nil
+}
+
+func xyzColor(rgb [3]float64, over1 bool) palette.Color {
+	var c [3]uint8
+	for i, v := range rgb {
+		if over1 {
+			c[i] = uint8(math.Round(max(0, min(255, v))))
+		} else {
+			c[i] = uint8(math.Round(clamp01(v) * 255))
+		}
+	}
+	return palette.Color{R: c[0], G: c[1], B: c[2]}
+}
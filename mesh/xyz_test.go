@@ -0,0 +1,46 @@
+package mesh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func TestParseXYZColorScale(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want palette.Color
+	}{
+		{"0-1", "0 0 0 0 0.5 1\n1 0 0 1.0 1 1\n", palette.Color{R: 0, G: 128, B: 255}},
+		{"0-255", "0 0 0 0 0.5 1\n1 0 0 255 255 255\n", palette.Color{R: 0, G: 1, B: 1}},
+		{"intensity", "0 0 0 9 0 128 255\n", palette.Color{R: 0, G: 128, B: 255}},
+	}
+	for _, tt := range tests {
+		m, err := ParseXYZ(strings.NewReader(tt.src), tt.name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m.Colors[0] != tt.want {
+			t.Errorf("%s: color %v, want %v", tt.name, m.Colors[0], tt.want)
+		}
+	}
+}
+
+func TestParseXYZSkipsBrokenLines(t *testing.T) {
+	src := "# comment\n0,0,0\n1;2\n1 2 x\n3 4 5\n"
+	report := diagnostic.NewReport(nil)
+	m, err := ParseXYZ(strings.NewReader(src), "points.xyz", report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Vertices) != 2 || m.Colors != nil {
+		t.Errorf("vertices %d colors %v, want 2 nil", len(m.Vertices), m.Colors)
+	}
+	items := report.Items()
+	if len(items) != 2 || items[0].Line != 3 || items[1].Line != 4 {
+		t.Errorf("reports %v, want lines 3 and 4", items)
+	}
+}
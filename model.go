@@ -0,0 +1,21 @@
+package model2minecraft
+
+import (
+	"fmt"
+
+	"github.com/aatomu/model2minecraft/mesh"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Convert Minecraft block/item model(e.g. "block/anvil", "minecraft:item/diamond_sword") in MinecraftDirectory
+//
+// Model pixels are scaled by ModelScale, object transform/grid/fill configuration is used.
+func (c *Converter) ConvertModel(model string) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nModel parse start...\n")
+
+	m, err := mesh.ParseModel(c.opt.MinecraftDirectory, palette.ParseResource(model), c.report)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertMesh(m, c.opt.ModelScale), nil
+}
@@ -0,0 +1,89 @@
+package model2minecraft
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Assets directory with model "block/<name>" and solid 16x16 textures "block/<texture>"
+func writeModelAssets(t *testing.T, name, model string, textures map[string]color.NRGBA) string {
+	t.Helper()
+	directory := t.TempDir()
+	files := map[string][]byte{"models/block/" + name + ".json": []byte(model)}
+	for texture, c := range textures {
+		img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		files["textures/block/"+texture+".png"] = encodePNG(t, img).Bytes()
+	}
+	for name, data := range files {
+		file := filepath.Join(directory, "minecraft", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+const modelCube = `{"from": [0, 0, 0], "to": [16, 16, 16], "faces": {
+  "north": {"texture": "#all"}, "south": {"texture": "#all"}, "west": {"texture": "#all"},
+  "east": {"texture": "#all"}, "up": {"texture": "#all"}, "down": {"texture": "#all"}
+}}`
+
+func TestConvertModelOutwardNormal(t *testing.T) {
+	// Blue cube with a thin plate on top: red up face, black down face sharing the top voxels
+	directory := writeModelAssets(t, "plate", `{
+  "elements": [
+    `+modelCube+`,
+    {"from": [0, 16.1, 0], "to": [16, 16.3, 16], "faces": {
+      "up": {"texture": "#red"}, "down": {"texture": "#black"}
+    }}
+  ],
+  "textures": {"all": "block/blue", "red": "block/red", "black": "block/black"}
+}`, map[string]color.NRGBA{"blue": {B: 255, A: 255}, "red": {R: 255, A: 255}, "black": {A: 255}})
+
+	c := newTestConverter(t, func(opt *Options) {
+		opt.MinecraftDirectory = directory
+		opt.MergePolicy = voxel.OutwardNormal
+	})
+	grid, err := c.ConvertModel("block/plate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Upward faces win over the downward plate bottom, ties keep the cube top
+	v, ok := grid.Get(voxel.Pos{X: 8, Y: 16, Z: 8})
+	if !ok || v.BlockID != "blue_wool" {
+		t.Errorf("top voxel %v %t, want blue_wool", v, ok)
+	}
+}
+
+func TestConvertModelDirectionalLight(t *testing.T) {
+	directory := writeModelAssets(t, "cube", `{
+  "elements": [`+modelCube+`],
+  "textures": {"all": "block/white"}
+}`, map[string]color.NRGBA{"white": {R: 255, G: 255, B: 255, A: 255}})
+
+	c := newTestConverter(t, func(opt *Options) {
+		opt.MinecraftDirectory = directory
+		opt.Shading = voxel.Shading{Directional: true, Direction: [3]float64{0, -1, 0}, Ambient: 0.1}
+	})
+	grid, err := c.ConvertModel("block/cube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Top face is lit, bottom face gets ambient only
+	top, _ := grid.Get(voxel.Pos{X: 8, Y: 16, Z: 8})
+	bottom, _ := grid.Get(voxel.Pos{X: 8, Y: 0, Z: 8})
+	if top.Color.R <= bottom.Color.R || top.BlockID != "white_wool" || bottom.BlockID != "black_wool" {
+		t.Errorf("top %v bottom %v, want lit white_wool over black_wool", top, bottom)
+	}
+}
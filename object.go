@@ -1,174 +1,199 @@
-package main
+package model2minecraft
 
 import (
+	"fmt"
+	"io"
 	"math"
-	"strconv"
-	"strings"
+	"path/filepath"
 	"sync"
+	"time"
+
+	"github.com/aatomu/model2minecraft/mesh"
+	"github.com/aatomu/model2minecraft/voxel"
 )
 
-func getStep(Pa, Pb, Pc [3]float64, spacing float64) (step float64) {
-	Distance := func(Xa, Ya, Za, Xb, Yb, Zb float64) float64 {
-		Xab := (Xa - Xb) * (Xa - Xb)
-		Yab := (Ya - Yb) * (Ya - Yb)
-		Zab := (Za - Zb) * (Za - Zb)
-		return math.Sqrt(Xab + Yab + Zab)
+// Convert .obj surface, .mtl and textures are read from ObjectDirectory
+func (c *Converter) ConvertOBJ(r io.Reader) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nObject parse start...\n")
+
+	name := sourceName(r, "object.obj")
+	m, err := mesh.ParseOBJ(r, name, mesh.ParseOptions{
+		Directory:     c.opt.ObjectDirectory,
+		Triangulation: c.opt.ObjectTriangulation,
+	}, c.report)
+	if err != nil {
+		return nil, err
 	}
-
-	Vab := Distance(Pa[0], Pa[1], Pa[2], Pb[0], Pb[1], Pb[2])
-	Vbc := Distance(Pb[0], Pb[1], Pb[2], Pc[0], Pc[1], Pc[2])
-	Vca := Distance(Pc[0], Pc[1], Pc[2], Pa[0], Pa[1], Pa[2])
-	Vmax := math.Max(math.Max(Vab, Vbc), Vca)
-	step = spacing / Vmax
-	return
+	return c.convertMesh(m, c.opt.ObjectScale), nil
 }
 
-func getPolygonPoints(step float64, Pa, Pb, Pc [3]float64) (points [][3]float64) {
-	// allocate slice capacity
-	points = make([][3]float64, 0, int(1/step)*int(1/step))
-
-	var lambdaA, lambdaB, lambdaC float64
-
-	for mulLambdaA := 0.0; mulLambdaA*step <= 1; mulLambdaA++ {
-		for mulLambdaB := 0.0; (mulLambdaA+mulLambdaB)*step <= 1; mulLambdaB++ {
-			lambdaA = mulLambdaA * step
-			lambdaB = mulLambdaB * step
-			lambdaC = 1.0 - lambdaA - lambdaB
-			x, y, z := weightedPoint3D(Pa, Pb, Pc, lambdaA, lambdaB, lambdaC)
+// Convert binary/ASCII .stl surface
+//
+// Facets use STLBlockId, facet color or ObjectDefaultColor.
+func (c *Converter) ConvertSTL(r io.Reader) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nSTL parse start...\n")
 
-			points = append(points, [3]float64{x, y, z})
+	m, err := mesh.ParseSTL(r, sourceName(r, "object.stl"), c.report)
+	if err != nil {
+		return nil, err
+	}
+	if c.opt.STLBlockId != "" {
+		m.Materials = map[string]*mesh.Material{"": {BlockID: c.opt.STLBlockId}}
+		for i := range m.Faces {
+			m.Faces[i].Material = ""
 		}
 	}
 
-	return
+	return c.convertMesh(m, c.opt.ObjectScale), nil
 }
 
-func getTexturePoints(step float64, Pa, Pb, Pc [2]float64) (points [][2]float64) {
-	// allocate slice capacity
-	points = make([][2]float64, 0, int(1/step)*int(1/step))
+// Convert .gltf/.glb surface with PBR base color
+//
+// External buffers and images are read next to the file, or from ObjectDirectory.
+func (c *Converter) ConvertGLTF(r io.Reader) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nglTF parse start...\n")
+
+	name := sourceName(r, "")
+	directory := c.opt.ObjectDirectory
+	if name != "" {
+		directory = filepath.Dir(name)
+	} else {
+		name = "object.gltf"
+	}
+	m, err := mesh.ParseGLTF(r, name, directory, c.report)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertMesh(m, c.opt.ObjectScale), nil
+}
 
-	var lambdaA, lambdaB, lambdaC float64
+// Scale, transform and voxelize
+func (c *Converter) convertMesh(m *mesh.Mesh, scale float64) *VoxelSet {
+	m.Scale(scale)
+	m.Apply(c.opt.ObjectTransform, c.opt.ObjectGridSpacing)
+	fmt.Fprintf(c.log, "Point:%d Face:%d Material:%d\n", len(m.Vertices), len(m.Faces), len(m.Materials))
 
-	for mulLambdaA := 0.0; mulLambdaA*step <= 1; mulLambdaA++ {
-		for mulLambdaB := 0.0; (mulLambdaA+mulLambdaB)*step <= 1; mulLambdaB++ {
-			lambdaA = mulLambdaA * step
-			lambdaB = mulLambdaB * step
-			lambdaC = 1.0 - lambdaA - lambdaB
-			x := Pa[0]*lambdaA + Pb[0]*lambdaB + Pc[0]*lambdaC
-			y := Pa[1]*lambdaA + Pb[1]*lambdaB + Pc[1]*lambdaC
+	return c.VoxelizeMesh(m)
+}
 
-			points = append(points, [2]float64{x, y})
-		}
+// Shrink mesh once by the voxel count of the first sampling, returns the grid fitting BlockBudget
+//
+// Re-voxelizing keeps more detail than decimating the grid, which is left for estimation errors.
+func (c *Converter) fitBudget(m *mesh.Mesh, grid *VoxelSet) *VoxelSet {
+	budget := c.opt.BlockBudget
+	if budget <= 0 {
+		return grid
+	}
+	if c.opt.RemoveHidden {
+		grid.RemoveHidden()
+	}
+	if grid.Len() <= budget {
+		return grid
 	}
 
-	return
+	// Surface voxels grow with the square of scale, filled voxels with the cube
+	exponent := 1.0 / 2.0
+	if c.opt.ObjectFillMode != voxel.Hollow && !c.opt.RemoveHidden {
+		exponent = 1.0 / 3.0
+	}
+	factor := math.Pow(float64(budget)/float64(grid.Len()), exponent) * 0.98
+	fmt.Fprintf(c.log, "Block budget %d: %d voxels, rescale x%.4f\n", budget, grid.Len(), factor)
+
+	// Anchor and Translate put the model around Translate, scaling about it keeps the placement
+	t := c.opt.ObjectTransform.Translate
+	for i, v := range m.Vertices {
+		m.Vertices[i] = [3]float64{t[0] + (v[0]-t[0])*factor, t[1] + (v[1]-t[1])*factor, t[2] + (v[2]-t[2])*factor}
+	}
+	return c.sampleMesh(m)
 }
 
-func weightedPoint3D(Pa, Pb, Pc [3]float64, lambdaA, lambdaB, lambdaC float64) (x, y, z float64) {
-	x = Pa[0]*lambdaA + Pb[0]*lambdaB + Pc[0]*lambdaC
-	y = Pa[1]*lambdaA + Pb[1]*lambdaB + Pc[1]*lambdaC
-	z = Pa[2]*lambdaA + Pb[2]*lambdaB + Pc[2]*lambdaC
-	return
+// Sample mesh surfaces in parallel, fill interior and finalize
+//
+// Samples are written in face order, so merged voxels do not depend on scheduling.
+func (c *Converter) VoxelizeMesh(m *mesh.Mesh) *VoxelSet {
+	start := time.Now()
+	grid := c.fitBudget(m, c.sampleMesh(m))
+	c.finalize(grid)
+	min, max := grid.Bounds()
+	w, h, d := grid.Size()
+	fmt.Fprintf(c.log, "\nObject parse duration: %s\n", time.Since(start))
+	fmt.Fprintf(c.log, "Min:[%d,%d,%d] Max:[%d,%d,%d] W:%d H:%d D:%d Voxel:%d\n", min.X, min.Y, min.Z, max.X, max.Y, max.Z, w, h, d, grid.Len())
+	return grid
 }
 
-func calcSurface(indexes []string, polygonVectors [][3]float64, textureVectors [][2]float64, texture [][]Color) (step float64, min [3]float64, max [3]float64, args []CommandArgument, usedBlock map[string]int) {
-	// Get surface polygon top
-	polygonPaIndex, _ := strconv.Atoi(strings.Split(indexes[0], "/")[0])
-	polygonPbIndex, _ := strconv.Atoi(strings.Split(indexes[1], "/")[0])
-	polygonPcIndex, _ := strconv.Atoi(strings.Split(indexes[2], "/")[0])
-	polygonPa := polygonVectors[polygonPaIndex-1]
-	polygonPb := polygonVectors[polygonPbIndex-1]
-	polygonPc := polygonVectors[polygonPcIndex-1]
-	// Get texture polygon top
-	texturePaIndex, _ := strconv.Atoi(strings.Split(indexes[0], "/")[1])
-	texturePbIndex, _ := strconv.Atoi(strings.Split(indexes[1], "/")[1])
-	texturePcIndex, _ := strconv.Atoi(strings.Split(indexes[2], "/")[1])
-	texturePa := textureVectors[texturePaIndex-1]
-	texturePb := textureVectors[texturePbIndex-1]
-	texturePc := textureVectors[texturePcIndex-1]
-
-	// Get min,max polygon top
-	for i := 0; i < 3; i++ {
-		// min
-		min[i] = math.Min(min[i], polygonPa[i])
-		min[i] = math.Min(min[i], polygonPb[i])
-		min[i] = math.Min(min[i], polygonPc[i])
-		// max
-		max[i] = math.Max(max[i], polygonPa[i])
-		max[i] = math.Max(max[i], polygonPb[i])
-		max[i] = math.Max(max[i], polygonPc[i])
+func (c *Converter) sampleMesh(m *mesh.Mesh) *VoxelSet {
+	start := time.Now()
+	grid := c.newGrid(c.opt.ObjectGridSpacing)
+	opt := mesh.SurfaceOptions{
+		GridSpacing:         c.opt.ObjectGridSpacing,
+		Connectivity:        c.opt.ObjectConnectivity,
+		UVYAxisUp:           c.opt.IsObjectUVYAxisUp,
+		Filter:              c.opt.ObjectTextureFilter,
+		Wrap:                c.opt.ObjectTextureWrap,
+		TintTexture:         c.opt.ObjectTintTexture,
+		MultiplyVertexColor: c.opt.ObjectVertexColorTexture,
+		DefaultColor:        c.opt.ObjectDefaultColor,
+		Area:                c.opt.MergePolicy == voxel.LargestArea,
 	}
 
-	step = getStep(polygonPa, polygonPb, polygonPc, objectGridSpacing)
-	wg := sync.WaitGroup{}
-	wg.Add(2)
-	var polygonPoints [][3]float64
-	go func() {
-		defer wg.Done()
-		polygonPoints = getPolygonPoints(step, polygonPa, polygonPb, polygonPc)
-	}()
-	var texturePoints [][2]float64
-	go func() {
-		defer wg.Done()
-		texturePoints = getTexturePoints(step, texturePa, texturePb, texturePc)
-	}()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		session = make(chan struct{}, c.opt.ParallelLimit)
+		done    int
+		// Sampled faces waiting for the earlier faces
+		results = make([][]voxel.Sample, len(m.Faces))
+		ready   = make([]bool, len(m.Faces))
+		next    int
+	)
+	for i, face := range m.Faces {
+		session <- struct{}{}
+		wg.Add(1)
+		go func(i int, fFace mesh.Face) {
+			defer func() {
+				<-session
+				wg.Done()
+			}()
+
+			samples, err := m.SampleFace(fFace, opt)
+			if err != nil {
+				if fFace.Line == 0 && fFace.Group != "" {
+					// No source line(glTF, binary files), the group names the primitive or facet
+					err = fmt.Errorf("%s: %w", fFace.Group, err)
+				}
+				c.report.Add("object", m.Name, fFace.Line, fmt.Errorf("skip face: %w", err))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i], ready[i] = samples, true
+			for ; next < len(m.Faces) && ready[next]; next++ {
+				for _, s := range results[next] {
+					s.Color = c.opt.Shading.Light(s.Color, s.Normal)
+					grid.WriteSample(s)
+				}
+				results[next] = nil
+			}
+			done++
+			fmt.Fprintf(c.log, "Face L%-8d Voxel:%-6d Now:%s Done(face/total):%d/%d\n", fFace.Line, len(samples), time.Since(start), done, len(m.Faces))
+		}(i, face)
+	}
 	wg.Wait()
-
-	usedBlock = map[string]int{}
-	const threshold = 1e-10
-
-	args = make([]CommandArgument, 0, len(polygonPoints))
-	for i := 0; i < len(polygonPoints); i++ {
-		polygonPoint := polygonPoints[i]
-		x := math.Round(polygonPoint[0]/objectGridSpacing) * objectGridSpacing
-		if math.Abs(x) < threshold {
-			x = 0
-		}
-		y := math.Round(polygonPoint[1]/objectGridSpacing) * objectGridSpacing
-		if math.Abs(y) < threshold {
-			y = 0
+	grid.Resolve()
+
+	if c.opt.ObjectFillMode != voxel.Hollow {
+		fillStart := time.Now()
+		var inside func(p voxel.Pos) bool
+		if c.opt.ObjectFillMode == voxel.Parity {
+			inside = m.ParityInterior(c.opt.ObjectGridSpacing)
+		} else {
+			inside = grid.FloodInterior()
 		}
-		z := math.Round(polygonPoint[2]/objectGridSpacing) * objectGridSpacing
-		if math.Abs(z) < threshold {
-			z = 0
-		}
-
-		// Image position mapping
-		//  Golang:   | Obj:
-		//   0 - X+   |  Y+
-		//   |        |  |
-		//   Y+       |  0 - X+
-
-		texturePoint := texturePoints[i]
-		// -1..1 => -width..width
-		textureX := math.Mod(texturePoint[0], 1)
-		if textureX < 0 {
-			textureX = 1 + textureX
-		}
-		textureIndexX := int(textureX * float64(len(texture)))
-		// -1..1 => height..-height
-		textureY := math.Mod(texturePoint[1], 1)
-		if isObjectUVYAxisUp {
-			textureY = 1 - textureY
-		}
-		if textureY < 0 {
-			textureY = 1 + textureY
-		}
-		textureIndexY := int(textureY * float64(len(texture[textureIndexX])))
-		texturePixel := texture[textureIndexX][textureIndexY]
-		blockId := getBlock(texturePixel)
-
-		args = append(args, CommandArgument{
-			color:   texturePixel,
-			blockId: blockId,
-			position: Position{
-				x: x,
-				y: y,
-				z: z,
-			},
+		filled := grid.FillInterior(inside, voxel.FillOptions{
+			BlockID:        c.opt.ObjectFillBlockId,
+			ShellThickness: c.opt.ObjectShellThickness,
 		})
-		usedBlock[blockId] = usedBlock[blockId] + 1
+		fmt.Fprintf(c.log, "\nFill interior: %d voxels, duration: %s\n", filled, time.Since(fillStart))
 	}
-
-	return
+	return grid
 }
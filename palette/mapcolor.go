@@ -0,0 +1,207 @@
+package palette
+
+import "fmt"
+
+// Map color id(Java 1.17+), 0: transparent
+type MapColor uint8
+
+// Map color brightness by relative height to the block north of it
+type MapShade uint8
+
+const (
+	ShadeLow    MapShade = iota // Lower than north block, ×180/255
+	ShadeFlat                   // Same height, ×220/255
+	ShadeHigh                   // Higher than north block, ×255/255
+	ShadeLowest                 // Not reachable by blocks, ×135/255
+)
+
+var mapShadeMultiplier = [4]uint16{180, 220, 255, 135}
+
+// Base colors of map color ids
+var mapColors = [...]Color{
+	{0, 0, 0},       // 0 none
+	{127, 178, 56},  // 1 grass
+	{247, 233, 163}, // 2 sand
+	{199, 199, 199}, // 3 wool
+	{255, 0, 0},     // 4 fire
+	{160, 160, 255}, // 5 ice
+	{167, 167, 167}, // 6 metal
+	{0, 124, 0},     // 7 plant
+	{255, 255, 255}, // 8 snow
+	{164, 168, 184}, // 9 clay
+	{151, 109, 77},  // 10 dirt
+	{112, 112, 112}, // 11 stone
+	{64, 64, 255},   // 12 water
+	{143, 119, 72},  // 13 wood
+	{255, 252, 245}, // 14 quartz
+	{216, 127, 51},  // 15 orange
+	{178, 76, 216},  // 16 magenta
+	{102, 153, 216}, // 17 light blue
+	{229, 229, 51},  // 18 yellow
+	{127, 204, 25},  // 19 lime
+	{242, 127, 165}, // 20 pink
+	{76, 76, 76},    // 21 gray
+	{153, 153, 153}, // 22 light gray
+	{76, 127, 153},  // 23 cyan
+	{127, 63, 178},  // 24 purple
+	{51, 76, 178},   // 25 blue
+	{102, 76, 51},   // 26 brown
+	{102, 127, 51},  // 27 green
+	{153, 51, 51},   // 28 red
+	{25, 25, 25},    // 29 black
+	{250, 238, 77},  // 30 gold
+	{92, 219, 213},  // 31 diamond
+	{74, 128, 255},  // 32 lapis
+	{0, 217, 58},    // 33 emerald
+	{129, 86, 49},   // 34 podzol
+	{112, 2, 0},     // 35 nether
+	{209, 177, 161}, // 36 white terracotta
+	{159, 82, 36},   // 37 orange terracotta
+	{149, 87, 108},  // 38 magenta terracotta
+	{112, 108, 138}, // 39 light blue terracotta
+	{186, 133, 36},  // 40 yellow terracotta
+	{103, 117, 53},  // 41 lime terracotta
+	{160, 77, 78},   // 42 pink terracotta
+	{57, 41, 35},    // 43 gray terracotta
+	{135, 107, 98},  // 44 light gray terracotta
+	{87, 92, 92},    // 45 cyan terracotta
+	{122, 73, 88},   // 46 purple terracotta
+	{76, 62, 92},    // 47 blue terracotta
+	{76, 50, 35},    // 48 brown terracotta
+	{76, 82, 42},    // 49 green terracotta
+	{142, 60, 46},   // 50 red terracotta
+	{37, 22, 16},    // 51 black terracotta
+	{189, 48, 49},   // 52 crimson nylium
+	{148, 63, 97},   // 53 crimson stem
+	{92, 25, 29},    // 54 crimson hyphae
+	{22, 126, 134},  // 55 warped nylium
+	{58, 142, 140},  // 56 warped stem
+	{86, 44, 62},    // 57 warped hyphae
+	{20, 180, 133},  // 58 warped wart block
+	{100, 100, 100}, // 59 deepslate
+	{216, 175, 147}, // 60 raw iron
+	{127, 167, 150}, // 61 glow lichen
+}
+
+// Full blocks usable for map art by map color
+var mapColorBlocks = map[MapColor][]string{
+	1:  {"grass_block", "slime_block"},
+	2:  {"sand", "sandstone", "birch_planks", "end_stone", "glowstone", "bone_block"},
+	3:  {"mushroom_stem"},
+	4:  {"tnt", "redstone_block"},
+	5:  {"ice", "packed_ice", "blue_ice"},
+	6:  {"iron_block"},
+	8:  {"snow_block"},
+	9:  {"clay"},
+	10: {"dirt", "coarse_dirt", "granite", "jungle_planks", "packed_mud"},
+	11: {"stone", "cobblestone", "andesite", "stone_bricks", "smooth_stone", "gravel"},
+	13: {"oak_planks"},
+	14: {"quartz_block", "diorite", "sea_lantern"},
+	15: {"acacia_planks", "terracotta", "red_sand", "pumpkin", "honeycomb_block"},
+	16: {"purpur_block"},
+	18: {"hay_block", "sponge", "bamboo_planks"},
+	19: {"melon"},
+	20: {"brain_coral_block"},
+	23: {"prismarine"},
+	24: {"amethyst_block", "mycelium"},
+	26: {"dark_oak_planks", "soul_sand", "soul_soil"},
+	27: {"moss_block", "dried_kelp_block"},
+	28: {"bricks", "mangrove_planks", "nether_wart_block", "red_mushroom_block"},
+	29: {"obsidian", "coal_block", "blackstone", "basalt", "crying_obsidian"},
+	30: {"gold_block"},
+	31: {"diamond_block", "prismarine_bricks", "dark_prismarine"},
+	32: {"lapis_block"},
+	33: {"emerald_block"},
+	34: {"spruce_planks", "podzol"},
+	35: {"netherrack", "nether_bricks", "magma_block", "red_nether_bricks"},
+	36: {"cherry_planks", "calcite"},
+	43: {"tuff"},
+	44: {"mud_bricks"},
+	45: {"mud"},
+	48: {"dripstone_block"},
+	52: {"crimson_nylium"},
+	53: {"crimson_planks"},
+	54: {"crimson_hyphae"},
+	55: {"warped_nylium"},
+	56: {"warped_planks"},
+	57: {"warped_hyphae"},
+	58: {"warped_wart_block"},
+	59: {"deepslate", "cobbled_deepslate", "polished_deepslate", "deepslate_bricks"},
+	60: {"raw_iron_block"},
+}
+
+// map[blockID]MapColor
+var mapColorTable = map[string]MapColor{}
+
+func init() {
+	for color, blocks := range mapColorBlocks {
+		for _, block := range blocks {
+			mapColorTable[block] = color
+		}
+	}
+
+	// Dyed blocks: white is snow, others are the dye colors
+	colors := []string{
+		"white", "orange", "magenta", "light_blue", "yellow", "lime", "pink", "gray",
+		"light_gray", "cyan", "purple", "blue", "brown", "green", "red", "black",
+	}
+	for i, c := range colors {
+		dye := MapColor(15 + i - 1)
+		if c == "white" {
+			dye = 8
+		}
+		for _, block := range []string{"_concrete", "_wool", "_concrete_powder", "_carpet"} {
+			mapColorBlocks[dye] = append(mapColorBlocks[dye], c+block)
+			mapColorTable[c+block] = dye
+		}
+		terracotta := MapColor(36 + i)
+		mapColorBlocks[terracotta] = append(mapColorBlocks[terracotta], c+"_terracotta")
+		mapColorTable[c+"_terracotta"] = terracotta
+	}
+}
+
+// First listed block of each map color passing the allowed/ignored patterns and presets
+func MapArtBlocks(opt Options) (blocks map[MapColor]string, err error) {
+	if err := ValidatePresets(opt.Presets); err != nil {
+		return nil, err
+	}
+	allowed, err := compilePatterns(opt.Allowed)
+	if err != nil {
+		return nil, fmt.Errorf("allowed block ids: %w", err)
+	}
+	ignored, err := compilePatterns(opt.Ignored)
+	if err != nil {
+		return nil, fmt.Errorf("ignored block ids: %w", err)
+	}
+
+	blocks = map[MapColor]string{}
+	for color, ids := range mapColorBlocks {
+		for _, id := range ids {
+			if matchName(id, allowed, ignored) && MatchPresets(id, opt.Presets) {
+				blocks[color] = id
+				break
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no map color block left after filtering")
+	}
+	return blocks, nil
+}
+
+// Map color of block, ok is false for blocks without a known map color
+func MapColorOf(blockID string) (color MapColor, ok bool) {
+	color, ok = mapColorTable[blockID]
+	return
+}
+
+// Displayed color of map color and shade
+func (m MapColor) Shade(shade MapShade) Color {
+	base := mapColors[int(m)%len(mapColors)]
+	multiplier := mapShadeMultiplier[shade&3]
+	return Color{
+		R: uint8(uint16(base.R) * multiplier / 255),
+		G: uint8(uint16(base.G) * multiplier / 255),
+		B: uint8(uint16(base.B) * multiplier / 255),
+	}
+}
@@ -0,0 +1,233 @@
+package palette
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Block property not written in blockstates json
+type Meta struct {
+	LightEmission int  // 0..15
+	Survival      bool // Obtainable in survival
+	Renewable     bool
+	Flammable     bool
+	TileEntity    bool
+}
+
+// Block filter presets
+var metaPresets = map[string]func(meta Meta) bool{
+	"survival":         func(meta Meta) bool { return meta.Survival },
+	"renewable":        func(meta Meta) bool { return meta.Renewable },
+	"non-flammable":    func(meta Meta) bool { return !meta.Flammable },
+	"no-tile-entities": func(meta Meta) bool { return !meta.TileEntity },
+	"no-light":         func(meta Meta) bool { return meta.LightEmission == 0 },
+}
+
+// Unlisted blocks: survival obtainable, non-renewable, non-flammable, no tile entity, no light
+var defaultMeta = Meta{Survival: true}
+
+var metaTable = map[string]Meta{
+	// Not obtainable
+	"bedrock":              {},
+	"spawner":              {TileEntity: true},
+	"trial_spawner":        {TileEntity: true},
+	"vault":                {TileEntity: true, LightEmission: 6},
+	"budding_amethyst":     {},
+	"reinforced_deepslate": {},
+	"barrier":              {},
+	"light":                {LightEmission: 15},
+	"structure_block":      {TileEntity: true},
+	"jigsaw":               {TileEntity: true},
+	"command_block":        {TileEntity: true},
+	"suspicious_sand":      {TileEntity: true},
+	"suspicious_gravel":    {TileEntity: true},
+	"petrified_oak_slab":   {},
+	"frosted_ice":          {},
+
+	// Light source
+	"glowstone":             {Survival: true, Renewable: true, LightEmission: 15},
+	"sea_lantern":           {Survival: true, Renewable: true, LightEmission: 15},
+	"shroomlight":           {Survival: true, Renewable: true, LightEmission: 15},
+	"jack_o_lantern":        {Survival: true, Renewable: true, LightEmission: 15},
+	"ochre_froglight":       {Survival: true, Renewable: true, LightEmission: 15},
+	"verdant_froglight":     {Survival: true, Renewable: true, LightEmission: 15},
+	"pearlescent_froglight": {Survival: true, Renewable: true, LightEmission: 15},
+	"beacon":                {Survival: true, LightEmission: 15, TileEntity: true},
+	"conduit":               {Survival: true, LightEmission: 15, TileEntity: true},
+	"end_rod":               {Survival: true, Renewable: true, LightEmission: 14},
+	"crying_obsidian":       {Survival: true, Renewable: true, LightEmission: 10},
+	"respawn_anchor":        {Survival: true},
+	"sculk_catalyst":        {Survival: true, Renewable: true, LightEmission: 6, TileEntity: true},
+	"magma_block":           {Survival: true, Renewable: true, LightEmission: 3},
+	"brewing_stand":         {Survival: true, Renewable: true, LightEmission: 1, TileEntity: true},
+	"amethyst_cluster":      {Survival: true, Renewable: true, LightEmission: 5},
+
+	// Tile entity
+	"chest":              {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"trapped_chest":      {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"barrel":             {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"ender_chest":        {Survival: true, Renewable: true, LightEmission: 7, TileEntity: true},
+	"furnace":            {Survival: true, Renewable: true, TileEntity: true},
+	"smoker":             {Survival: true, Renewable: true, TileEntity: true},
+	"blast_furnace":      {Survival: true, Renewable: true, TileEntity: true},
+	"hopper":             {Survival: true, Renewable: true, TileEntity: true},
+	"dispenser":          {Survival: true, Renewable: true, TileEntity: true},
+	"dropper":            {Survival: true, Renewable: true, TileEntity: true},
+	"crafter":            {Survival: true, Renewable: true, TileEntity: true},
+	"jukebox":            {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"lectern":            {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"enchanting_table":   {Survival: true, Renewable: true, LightEmission: 7, TileEntity: true},
+	"bee_nest":           {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"beehive":            {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"chiseled_bookshelf": {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"daylight_detector":  {Survival: true, Renewable: true, Flammable: true, TileEntity: true},
+	"bell":               {Survival: true, TileEntity: true},
+	"decorated_pot":      {Survival: true, Renewable: true, TileEntity: true},
+	"sculk_sensor":       {Survival: true, Renewable: true, LightEmission: 1, TileEntity: true},
+	"sculk_shrieker":     {Survival: true, Renewable: true, TileEntity: true},
+
+	// Flammable
+	"bookshelf":        {Survival: true, Renewable: true, Flammable: true},
+	"hay_block":        {Survival: true, Renewable: true, Flammable: true},
+	"dried_kelp_block": {Survival: true, Renewable: true, Flammable: true},
+	"coal_block":       {Survival: true, Renewable: true, Flammable: true},
+	"target":           {Survival: true, Renewable: true, Flammable: true},
+	"tnt":              {Survival: true, Renewable: true, Flammable: true},
+	"scaffolding":      {Survival: true, Renewable: true, Flammable: true},
+	"bamboo_mosaic":    {Survival: true, Renewable: true, Flammable: true},
+	"bamboo_block":     {Survival: true, Renewable: true, Flammable: true},
+	"note_block":       {Survival: true, Renewable: true, Flammable: true},
+
+	// Renewable
+	"stone":              {Survival: true, Renewable: true},
+	"cobblestone":        {Survival: true, Renewable: true},
+	"mossy_cobblestone":  {Survival: true, Renewable: true},
+	"stone_bricks":       {Survival: true, Renewable: true},
+	"mossy_stone_bricks": {Survival: true, Renewable: true},
+	"smooth_stone":       {Survival: true, Renewable: true},
+	"basalt":             {Survival: true, Renewable: true},
+	"smooth_basalt":      {Survival: true, Renewable: true},
+	"obsidian":           {Survival: true, Renewable: true},
+	"sand":               {Survival: true, Renewable: true},
+	"red_sand":           {Survival: true, Renewable: true},
+	"gravel":             {Survival: true, Renewable: true},
+	"dirt":               {Survival: true, Renewable: true},
+	"mud":                {Survival: true, Renewable: true},
+	"packed_mud":         {Survival: true, Renewable: true},
+	"mud_bricks":         {Survival: true, Renewable: true},
+	"clay":               {Survival: true, Renewable: true},
+	"terracotta":         {Survival: true, Renewable: true},
+	"bricks":             {Survival: true, Renewable: true},
+	"soul_sand":          {Survival: true, Renewable: true},
+	"soul_soil":          {Survival: true, Renewable: true},
+	"nether_bricks":      {Survival: true, Renewable: true},
+	"red_nether_bricks":  {Survival: true, Renewable: true},
+	"nether_wart_block":  {Survival: true, Renewable: true},
+	"warped_wart_block":  {Survival: true, Renewable: true},
+	"ice":                {Survival: true, Renewable: true},
+	"packed_ice":         {Survival: true, Renewable: true},
+	"blue_ice":           {Survival: true, Renewable: true},
+	"snow_block":         {Survival: true, Renewable: true},
+	"slime_block":        {Survival: true, Renewable: true},
+	"honey_block":        {Survival: true, Renewable: true},
+	"honeycomb_block":    {Survival: true, Renewable: true},
+	"moss_block":         {Survival: true, Renewable: true},
+	"sponge":             {Survival: true, Renewable: true},
+	"wet_sponge":         {Survival: true, Renewable: true},
+	"prismarine":         {Survival: true, Renewable: true},
+	"prismarine_bricks":  {Survival: true, Renewable: true},
+	"dark_prismarine":    {Survival: true, Renewable: true},
+	"iron_block":         {Survival: true, Renewable: true},
+	"gold_block":         {Survival: true, Renewable: true},
+	"emerald_block":      {Survival: true, Renewable: true},
+	"lapis_block":        {Survival: true, Renewable: true},
+	"redstone_block":     {Survival: true, Renewable: true},
+	"copper_block":       {Survival: true, Renewable: true},
+	"amethyst_block":     {Survival: true, Renewable: true},
+	"pointed_dripstone":  {Survival: true, Renewable: true},
+	"dripstone_block":    {Survival: true, Renewable: true},
+	"cobweb":             {Survival: true, Renewable: true},
+}
+
+func init() {
+	colors := []string{
+		"white", "orange", "magenta", "light_blue", "yellow", "lime", "pink", "gray",
+		"light_gray", "cyan", "purple", "blue", "brown", "green", "red", "black",
+	}
+	for _, c := range colors {
+		metaTable[c+"_wool"] = Meta{Survival: true, Renewable: true, Flammable: true}
+		metaTable[c+"_carpet"] = Meta{Survival: true, Renewable: true, Flammable: true}
+		metaTable[c+"_concrete"] = Meta{Survival: true, Renewable: true}
+		metaTable[c+"_concrete_powder"] = Meta{Survival: true, Renewable: true}
+		metaTable[c+"_terracotta"] = Meta{Survival: true, Renewable: true}
+		metaTable[c+"_glazed_terracotta"] = Meta{Survival: true, Renewable: true}
+		metaTable[c+"_stained_glass"] = Meta{Survival: true, Renewable: true}
+		metaTable[c+"_shulker_box"] = Meta{Survival: true, Renewable: true, TileEntity: true}
+		metaTable[c+"_bed"] = Meta{Survival: true, Renewable: true, TileEntity: true}
+		metaTable[c+"_banner"] = Meta{Survival: true, Renewable: true, Flammable: true, TileEntity: true}
+	}
+
+	woods := []string{"oak", "spruce", "birch", "jungle", "acacia", "dark_oak", "mangrove", "cherry", "pale_oak", "bamboo"}
+	for _, w := range woods {
+		metaTable[w+"_planks"] = Meta{Survival: true, Renewable: true, Flammable: true}
+		metaTable[w+"_leaves"] = Meta{Survival: true, Renewable: true, Flammable: true}
+	}
+	for _, w := range []string{"crimson", "warped"} {
+		metaTable[w+"_planks"] = Meta{Survival: true, Renewable: true}
+	}
+
+	for _, coral := range []string{"tube", "brain", "bubble", "fire", "horn"} {
+		metaTable[coral+"_coral_block"] = Meta{Survival: true}
+		metaTable["dead_"+coral+"_coral_block"] = Meta{Survival: true}
+	}
+
+	// Copper weathering and waxed variants
+	for _, prefix := range []string{"", "waxed_"} {
+		for _, state := range []string{"", "exposed_", "weathered_", "oxidized_"} {
+			full := prefix + state + "copper"
+			if state == "" {
+				full += "_block"
+			}
+			metaTable[full] = Meta{Survival: true, Renewable: true}
+			for _, block := range []string{"cut_copper", "chiseled_copper", "copper_grate"} {
+				metaTable[prefix+state+block] = Meta{Survival: true, Renewable: true}
+			}
+		}
+	}
+
+	for _, stone := range []string{"stone", "cobblestone", "stone_bricks", "mossy_stone_bricks", "cracked_stone_bricks", "chiseled_stone_bricks", "deepslate"} {
+		metaTable["infested_"+stone] = Meta{}
+	}
+}
+
+func GetMeta(blockID string) Meta {
+	if meta, ok := metaTable[blockID]; ok {
+		return meta
+	}
+	return defaultMeta
+}
+
+// Check block meta matches all presets
+func MatchPresets(blockID string, presets []string) bool {
+	meta := GetMeta(blockID)
+	for _, preset := range presets {
+		if !metaPresets[preset](meta) {
+			return false
+		}
+	}
+	return true
+}
+
+func ValidatePresets(presets []string) error {
+	for _, preset := range presets {
+		if _, ok := metaPresets[preset]; !ok {
+			names := []string{}
+			for name := range metaPresets {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("unknown block filter preset %q, available: %v", preset, names)
+		}
+	}
+	return nil
+}
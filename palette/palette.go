@@ -0,0 +1,281 @@
+// Package palette loads Minecraft blocks usable for conversion and their colors.
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+)
+
+// 0..255 RGB color
+type Color struct {
+	R, G, B uint8
+}
+
+type Block struct {
+	ID    string
+	Color Color
+}
+
+// Minecraft resource location "namespace:path"
+type Resource struct {
+	Namespace string
+	Path      string
+}
+
+type Options struct {
+	Directory string   // Assets extracted from version.jar
+	Allowed   []string // Allowed regex patterns
+	Ignored   []string // Ignored regex patterns
+	Presets   []string // Block meta presets
+}
+
+// Scan blockstates and filter the blocks with all sides same texture
+func Load(opt Options, report *diagnostic.Report) ([]Block, error) {
+	if err := ValidatePresets(opt.Presets); err != nil {
+		return nil, err
+	}
+	models, err := ScanBlockModel(opt.Directory, report)
+	if err != nil {
+		return nil, err
+	}
+	return Filter(models, opt, report)
+}
+
+// map[blockID]model of stateless blocks
+func ScanBlockModel(directory string, report *diagnostic.Report) (blockModelList map[string]Resource, err error) {
+	blockModelList = map[string]Resource{}
+	jsonModels := 0
+
+	type blockstates struct {
+		Variants map[string]interface{} `json:"variants"`
+	}
+
+	// Scan Model By Dir
+	root := filepath.Join(directory, "minecraft", "blockstates")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			report.Add("block", path, 0, err)
+			return nil
+		}
+		// check json file
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		jsonModels++
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			report.Add("block", path, 0, err)
+			return nil
+		}
+		var states blockstates
+		if err := json.Unmarshal(b, &states); err != nil {
+			report.Add("block", path, 0, fmt.Errorf("parse blockstates: %w", err))
+			return nil
+		}
+
+		op, ok := states.Variants[""]
+		if !ok {
+			return nil
+		}
+
+		var modelPath string
+		switch modelJson := op.(type) {
+		case []interface{}: // Random Rotate Texture(Array)
+			if len(modelJson) > 0 {
+				if m, ok := modelJson[0].(map[string]interface{}); ok {
+					modelPath, _ = m["model"].(string)
+				}
+			}
+
+		case map[string]interface{}: // Not Random Rotate Texture
+			modelPath, _ = modelJson["model"].(string)
+		}
+		if modelPath == "" {
+			report.Add("block", path, 0, fmt.Errorf("variant has no model"))
+			return nil
+		}
+		blockModelList[removeExt(path)] = ParseResource(modelPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", root, err)
+	}
+
+	if jsonModels == 0 {
+		return nil, fmt.Errorf("no blockstates found in %s", root)
+	}
+	return
+}
+
+func Filter(blockModelList map[string]Resource, opt Options, report *diagnostic.Report) (blockList []Block, err error) {
+	blockList = []Block{}
+
+	allowed, err := compilePatterns(opt.Allowed)
+	if err != nil {
+		return nil, fmt.Errorf("allowed block ids: %w", err)
+	}
+	ignored, err := compilePatterns(opt.Ignored)
+	if err != nil {
+		return nil, fmt.Errorf("ignored block ids: %w", err)
+	}
+
+	type Model struct {
+		Parent   string            `json:"parent"`
+		Textures map[string]string `json:"textures"`
+	}
+
+	for blockID, blockModel := range blockModelList {
+		modelFile := filepath.Join(opt.Directory, blockModel.Namespace, "models", blockModel.Path+".json")
+		b, err := os.ReadFile(modelFile)
+		if err != nil {
+			report.Add("block", modelFile, 0, err)
+			continue
+		}
+
+		var model Model
+		if err := json.Unmarshal(b, &model); err != nil {
+			report.Add("block", modelFile, 0, fmt.Errorf("parse model: %w", err))
+			continue
+		}
+
+		imagePath, ok := model.Textures["all"]
+		if !ok {
+			continue
+		}
+
+		// name filter
+		if !matchName(blockID, allowed, ignored) {
+			continue
+		}
+
+		// meta filter
+		if !MatchPresets(blockID, opt.Presets) {
+			continue
+		}
+
+		// block to color
+		blockImagePath := TexturePath(opt.Directory, ParseResource(imagePath))
+		color, err := averageColor(blockImagePath)
+		if err != nil {
+			report.Add("block", blockImagePath, 0, err)
+			continue
+		}
+
+		blockList = append(blockList,
+			Block{
+				ID:    blockID,
+				Color: color,
+			})
+	}
+
+	slices.SortFunc(blockList, func(a, b Block) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if len(blockList) == 0 {
+		return nil, fmt.Errorf("no block left after filtering")
+	}
+	return
+}
+
+// Texture png path of resource
+func TexturePath(directory string, texture Resource) string {
+	return filepath.Join(directory, texture.Namespace, "textures", texture.Path+".png")
+}
+
+func averageColor(imagePath string) (c Color, err error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return c, fmt.Errorf("decode texture: %w", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return c, fmt.Errorf("empty texture")
+	}
+
+	var red, green, blue int
+	var pixel int
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			red += int(r >> 8)
+			green += int(g >> 8)
+			blue += int(b >> 8)
+			pixel++
+		}
+	}
+
+	return Color{
+		R: uint8(red / pixel),
+		G: uint8(green / pixel),
+		B: uint8(blue / pixel),
+	}, nil
+}
+
+// Block id matches some allowed pattern and no ignored pattern
+func matchName(blockID string, allowed, ignored []*regexp.Regexp) bool {
+	isAllowed := false
+	for _, filterBlockID := range allowed {
+		if filterBlockID.MatchString(blockID) {
+			isAllowed = true
+			break
+		}
+	}
+	if !isAllowed {
+		return false
+	}
+
+	for _, filterBlockID := range ignored {
+		if filterBlockID.MatchString(blockID) {
+			return false
+		}
+	}
+	return true
+}
+
+func compilePatterns(patterns []string) (r []*regexp.Regexp, err error) {
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, pattern)
+	}
+	return
+}
+
+func removeExt(s string) string {
+	return filepath.Base(s[:len(s)-len(filepath.Ext(s))])
+}
+
+func ParseResource(p string) Resource {
+	modelPath := strings.SplitN(p, ":", 2)
+	if len(modelPath) == 1 {
+		return Resource{
+			Namespace: "minecraft",
+			Path:      modelPath[0],
+		}
+	}
+	return Resource{
+		Namespace: modelPath[0],
+		Path:      modelPath[1],
+	}
+}
@@ -0,0 +1,78 @@
+package palette
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Block physics behaviour
+type Physics int
+
+const (
+	Solid       Physics = iota // Stays in place
+	Gravity                    // Falls when nothing is below (sand, gravel, concrete powder...)
+	NeedSupport                // Breaks when nothing is below (carpet, torch, rail...)
+	Fluid                      // Flows when nothing is below
+)
+
+// Block ID regex patterns by physics behaviour
+var (
+	gravityBlockIds = []string{
+		"^(red_|suspicious_)?sand$", "^(suspicious_)?gravel$", "concrete_powder$",
+		"anvil$", "^dragon_egg$", "^scaffolding$", "^pointed_dripstone$",
+	}
+	needSupportBlockIds = []string{
+		"carpet$", "torch$", "lantern$", "rail$", "pressure_plate$", "sapling$",
+		"^redstone_wire$", "^repeater$", "^comparator$", "^snow$", "^cactus$",
+		"^sugar_cane$", "^bamboo$", "^kelp$", "^lily_pad$", "^flower_pot$",
+	}
+	fluidBlockIds = []string{"^water$", "^lava$", "^bubble_column$"}
+
+	physicsCache      sync.Map // map[string]Physics
+	physicsPatternSet = []struct {
+		physics  Physics
+		patterns []*regexp.Regexp
+	}{
+		{Gravity, mustCompilePatterns(gravityBlockIds)},
+		{NeedSupport, mustCompilePatterns(needSupportBlockIds)},
+		{Fluid, mustCompilePatterns(fluidBlockIds)},
+	}
+)
+
+func mustCompilePatterns(patterns []string) (r []*regexp.Regexp) {
+	for _, p := range patterns {
+		r = append(r, regexp.MustCompile(p))
+	}
+	return
+}
+
+func PhysicsOf(blockID string) Physics {
+	if physics, ok := physicsCache.Load(blockID); ok {
+		return physics.(Physics)
+	}
+
+	physics := Solid
+	for _, set := range physicsPatternSet {
+		for _, pattern := range set.patterns {
+			if pattern.MatchString(blockID) {
+				physics = set.physics
+				break
+			}
+		}
+		if physics != Solid {
+			break
+		}
+	}
+
+	physicsCache.Store(blockID, physics)
+	return physics
+}
+
+// Block can hold the block above it
+func CanSupport(blockID string) bool {
+	switch PhysicsOf(blockID) {
+	case Solid, Gravity:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package model2minecraft
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aatomu/model2minecraft/mesh"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Convert ASCII/binary .ply, faces are sampled as surfaces when exist
+func (c *Converter) ConvertPLY(r io.Reader) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nPLY parse start...\n")
+
+	m, err := mesh.ParsePLY(r, sourceName(r, "points.ply"), c.opt.ObjectTriangulation, c.report)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Faces) > 0 {
+		return c.convertMesh(m, c.opt.ObjectScale), nil
+	}
+	return c.convertPoints(m), nil
+}
+
+// Convert .xyz point cloud
+func (c *Converter) ConvertXYZ(r io.Reader) (*VoxelSet, error) {
+	fmt.Fprintf(c.log, "\nXYZ parse start...\n")
+
+	m, err := mesh.ParseXYZ(r, sourceName(r, "points.xyz"), c.report)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertPoints(m), nil
+}
+
+// Bin points into the grid, points sharing a voxel are merged by MergePolicy(AverageColor, LabAverage...)
+//
+// Voxels with fewer than PointMinimumCount points are noise.
+func (c *Converter) convertPoints(m *mesh.Mesh) *VoxelSet {
+	start := time.Now()
+	m.Scale(c.opt.ObjectScale)
+	m.Apply(c.opt.ObjectTransform, c.opt.ObjectGridSpacing)
+	fmt.Fprintf(c.log, "Point:%d Colored:%t\n", len(m.Vertices), len(m.Colors) > 0)
+
+	grid := c.newGrid(c.opt.ObjectGridSpacing)
+	positions := make([]voxel.Pos, len(m.Vertices))
+	counts := map[voxel.Pos]int{}
+	for i, v := range m.Vertices {
+		positions[i] = grid.ToPos(voxel.Position{X: v[0], Y: v[1], Z: v[2]})
+		counts[positions[i]]++
+	}
+
+	noise := 0
+	for _, n := range counts {
+		if n < c.opt.PointMinimumCount {
+			noise++
+		}
+	}
+	// Written in point order, so merged voxels follow the file
+	for i, p := range positions {
+		if counts[p] < c.opt.PointMinimumCount {
+			continue
+		}
+		color := c.opt.ObjectDefaultColor
+		if len(m.Colors) > 0 {
+			color = m.Colors[i]
+		}
+		grid.Write(p, color, "")
+	}
+	c.finalize(grid)
+
+	lo, hi := grid.Bounds()
+	fmt.Fprintf(c.log, "\nPoint binning duration: %s Voxel:%d Noise:%d Bounds:%v-%v\n", time.Since(start), grid.Len(), noise, lo, hi)
+	return grid
+}
@@ -0,0 +1,52 @@
+package model2minecraft
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+func TestConvertXYZMergePolicy(t *testing.T) {
+	// Three points share voxel 0, one point alone is noise
+	src := "0 0 0 255 0 0\n0.1 0 0 255 0 0\n-0.1 0 0 0 0 255\n5 0 0 0 0 255\n"
+
+	tests := []struct {
+		policy voxel.MergePolicy
+		want   palette.Color
+	}{
+		{voxel.FirstWins, palette.Color{R: 255}},
+		{voxel.LastWins, palette.Color{B: 255}},
+		{voxel.MajorityVote, palette.Color{R: 255}},
+		{voxel.AverageColor, palette.Color{R: 170, B: 85}},
+	}
+	for _, tt := range tests {
+		c := newTestConverter(t, func(opt *Options) {
+			opt.MergePolicy = tt.policy
+			opt.PointMinimumCount = 2
+		})
+		grid, err := c.ConvertXYZ(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if grid.Len() != 1 {
+			t.Fatalf("policy %d: %d voxels, want 1", tt.policy, grid.Len())
+		}
+		if v, _ := grid.Get(voxel.Pos{}); v.Color != tt.want {
+			t.Errorf("policy %d: color %v, want %v", tt.policy, v.Color, tt.want)
+		}
+	}
+
+	// Lab average differs from the sRGB average
+	c := newTestConverter(t, func(opt *Options) {
+		opt.MergePolicy = voxel.LabAverage
+	})
+	grid, err := c.ConvertXYZ(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := grid.Get(voxel.Pos{}); v.Color == (palette.Color{R: 170, B: 85}) {
+		t.Errorf("LabAverage color %v is the sRGB average", v.Color)
+	}
+}
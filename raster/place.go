@@ -0,0 +1,127 @@
+package raster
+
+import (
+	"math"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Plane of placed pixels, named by the side the image faces
+type Orientation int
+
+const (
+	WallSouth Orientation = iota // XY plane facing south(+Z), image right: +X
+	WallNorth                    // XY plane facing north(-Z), image right: -X
+	WallEast                     // ZY plane facing east(+X), image right: -Z
+	WallWest                     // ZY plane facing west(-X), image right: +Z
+	Floor                        // XZ plane seen from above, image top: north(-Z)
+	Ceiling                      // XZ plane seen from below, image top: north(-Z)
+)
+
+// Applied in order: flip, Orientation, Rotation
+type Placement struct {
+	Orientation    Orientation
+	Rotation       [3]float64 // Euler degrees X→Y→Z around the origin
+	FlipHorizontal bool       // Mirror left and right
+	FlipVertical   bool       // Mirror top and bottom
+}
+
+// World position samples of pixels
+//
+// Rotations off the grid axes sample each pixel 2×2 times, so no gap is left between pixels.
+func (p Placement) Place(pixels []Pixel) (samples []voxel.Sample) {
+	if len(pixels) == 0 {
+		return nil
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, pixel := range pixels {
+		minX, maxX = math.Min(minX, pixel.X), math.Max(maxX, pixel.X)
+		minY, maxY = math.Min(minY, pixel.Y), math.Max(maxY, pixel.Y)
+	}
+
+	rotation := eulerMatrix(p.Rotation)
+	offsets := [][2]float64{{0, 0}}
+	if !axisAligned(rotation) {
+		offsets = [][2]float64{{-0.25, -0.25}, {-0.25, 0.25}, {0.25, -0.25}, {0.25, 0.25}}
+	}
+	normal := rotation.apply(p.orient(0, 0, 1))
+
+	for _, pixel := range pixels {
+		x, y := pixel.X, pixel.Y
+		if p.FlipHorizontal {
+			x = minX + maxX - x
+		}
+		if p.FlipVertical {
+			y = minY + maxY - y
+		}
+		for _, offset := range offsets {
+			v := rotation.apply(p.orient(x+offset[0], y+offset[1], 0))
+			samples = append(samples, voxel.Sample{
+				Position: voxel.Position{X: v[0], Y: v[1], Z: v[2]},
+				Color:    pixel.Color,
+				Normal:   normal,
+			})
+		}
+	}
+	return samples
+}
+
+// Image coordinate(x: right, y: up, z: front) to world
+func (p Placement) orient(x, y, z float64) [3]float64 {
+	switch p.Orientation {
+	case WallNorth:
+		return [3]float64{-x, y, -z}
+	case WallEast:
+		return [3]float64{z, y, -x}
+	case WallWest:
+		return [3]float64{-z, y, x}
+	case Floor:
+		return [3]float64{x, z, -y}
+	case Ceiling:
+		return [3]float64{-x, -z, -y}
+	}
+	return [3]float64{x, y, z}
+}
+
+type matrix [3][3]float64
+
+func (a matrix) mul(b matrix) (m matrix) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return
+}
+
+func (a matrix) apply(v [3]float64) [3]float64 {
+	return [3]float64{
+		a[0][0]*v[0] + a[0][1]*v[1] + a[0][2]*v[2],
+		a[1][0]*v[0] + a[1][1]*v[1] + a[1][2]*v[2],
+		a[2][0]*v[0] + a[2][1]*v[1] + a[2][2]*v[2],
+	}
+}
+
+func eulerMatrix(deg [3]float64) matrix {
+	x, y, z := deg[0]*math.Pi/180, deg[1]*math.Pi/180, deg[2]*math.Pi/180
+	rx := matrix{{1, 0, 0}, {0, math.Cos(x), -math.Sin(x)}, {0, math.Sin(x), math.Cos(x)}}
+	ry := matrix{{math.Cos(y), 0, math.Sin(y)}, {0, 1, 0}, {-math.Sin(y), 0, math.Cos(y)}}
+	rz := matrix{{math.Cos(z), -math.Sin(z), 0}, {math.Sin(z), math.Cos(z), 0}, {0, 0, 1}}
+	return rz.mul(ry).mul(rx)
+}
+
+// Every entry is 0 or ±1, multiples of 90 degrees
+func axisAligned(m matrix) bool {
+	const epsilon = 1e-9
+	for _, row := range m {
+		for _, v := range row {
+			if a := math.Abs(v); a > epsilon && math.Abs(a-1) > epsilon {
+				return false
+			}
+		}
+	}
+	return true
+}
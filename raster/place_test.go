@@ -0,0 +1,107 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// 4×3 image, top right pixel is red
+func markedPixels() []Pixel {
+	var pixels []Pixel
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 3; y++ {
+			p := Pixel{X: float64(x), Y: float64(y)}
+			if x == 3 && y == 2 {
+				p.Color = palette.Color{R: 255}
+			}
+			pixels = append(pixels, p)
+		}
+	}
+	return pixels
+}
+
+func near(a, b [3]float64) bool {
+	const epsilon = 1e-9
+	return math.Abs(a[0]-b[0]) < epsilon && math.Abs(a[1]-b[1]) < epsilon && math.Abs(a[2]-b[2]) < epsilon
+}
+
+func TestPlacementPlace(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement Placement
+		corner    [3]float64 // Top right pixel
+		normal    [3]float64
+	}{
+		{"wall south", Placement{Orientation: WallSouth}, [3]float64{3, 2, 0}, [3]float64{0, 0, 1}},
+		{"wall north", Placement{Orientation: WallNorth}, [3]float64{-3, 2, 0}, [3]float64{0, 0, -1}},
+		{"wall east", Placement{Orientation: WallEast}, [3]float64{0, 2, -3}, [3]float64{1, 0, 0}},
+		{"wall west", Placement{Orientation: WallWest}, [3]float64{0, 2, 3}, [3]float64{-1, 0, 0}},
+		{"floor", Placement{Orientation: Floor}, [3]float64{3, 0, -2}, [3]float64{0, 1, 0}},
+		{"ceiling", Placement{Orientation: Ceiling}, [3]float64{-3, 0, -2}, [3]float64{0, -1, 0}},
+		{"flip horizontal", Placement{FlipHorizontal: true}, [3]float64{0, 2, 0}, [3]float64{0, 0, 1}},
+		{"flip vertical", Placement{FlipVertical: true}, [3]float64{3, 0, 0}, [3]float64{0, 0, 1}},
+		{"flip both", Placement{FlipHorizontal: true, FlipVertical: true}, [3]float64{0, 0, 0}, [3]float64{0, 0, 1}},
+		{"floor flip vertical", Placement{Orientation: Floor, FlipVertical: true}, [3]float64{3, 0, 0}, [3]float64{0, 1, 0}},
+		{"rotate y 90", Placement{Rotation: [3]float64{0, 90, 0}}, [3]float64{0, 2, -3}, [3]float64{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		samples := tt.placement.Place(markedPixels())
+		if len(samples) != 12 {
+			t.Errorf("%s: %d samples, want 12", tt.name, len(samples))
+			continue
+		}
+		for _, s := range samples {
+			if s.Color.R == 0 {
+				continue
+			}
+			if p := [3]float64{s.Position.X, s.Position.Y, s.Position.Z}; !near(p, tt.corner) {
+				t.Errorf("%s: top right at %v, want %v", tt.name, p, tt.corner)
+			}
+			if !near(s.Normal, tt.normal) {
+				t.Errorf("%s: normal %v, want %v", tt.name, s.Normal, tt.normal)
+			}
+		}
+	}
+}
+
+func TestPlacementSupersample(t *testing.T) {
+	// Off the grid axes every pixel is sampled 2×2 times around its rotated center
+	samples := Placement{Rotation: [3]float64{0, 0, 45}}.Place(markedPixels())
+	if len(samples) != 48 {
+		t.Fatalf("%d samples, want 48", len(samples))
+	}
+	var sum [3]float64
+	n := 0
+	for _, s := range samples {
+		if s.Color.R == 0 {
+			continue
+		}
+		sum[0] += s.Position.X
+		sum[1] += s.Position.Y
+		sum[2] += s.Position.Z
+		n++
+	}
+	sin, cos := math.Sincos(math.Pi / 4)
+	want := [3]float64{3*cos - 2*sin, 3*sin + 2*cos, 0}
+	if center := [3]float64{sum[0] / 4, sum[1] / 4, sum[2] / 4}; n != 4 || !near(center, want) {
+		t.Errorf("%d samples around %v, want 4 around %v", n, center, want)
+	}
+
+	// Rounded samples leave no hole: blocks centered inside the sampled area are filled
+	filled := map[[2]int]bool{}
+	for _, s := range samples {
+		filled[[2]int{int(math.Round(s.Position.X)), int(math.Round(s.Position.Y))}] = true
+	}
+	for x := -3; x <= 4; x++ {
+		for y := 0; y <= 5; y++ {
+			// Image coordinate of the block center
+			u := float64(x)*cos + float64(y)*sin
+			v := -float64(x)*sin + float64(y)*cos
+			if u >= -0.25 && u <= 3.25 && v >= -0.25 && v <= 2.25 && !filled[[2]int{x, y}] {
+				t.Errorf("hole at %d,%d", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package raster decodes images into pixels.
+package raster
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+type Pixel struct {
+	Color palette.Color
+	X, Y  float64 // Y+ is up
+}
+
+func Parse(r io.Reader) (p []Pixel, err error) {
+	img, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return Pixels(img), nil
+}
+
+// Decode .png/.jpeg
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	return img, nil
+}
+
+func Pixels(img image.Image) (p []Pixel) {
+	bounds := img.Bounds()
+
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			p = append(p, Pixel{
+				Color: palette.Color{
+					R: uint8(r >> 8),
+					G: uint8(g >> 8),
+					B: uint8(b >> 8),
+				},
+				X: float64(x),
+				Y: float64(bounds.Max.Y - y),
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,185 @@
+package raster
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Resampling filter
+type Filter int
+
+const (
+	Nearest  Filter = iota // Sharp pixel art
+	Box                    // Average of covered pixels
+	Bilinear               // Linear interpolation
+	Lanczos                // Lanczos-3, sharp photos
+)
+
+// Applied in order: Crop, resize
+//
+// Only Width or Height keeps the aspect ratio, both fit the image inside Width×Height.
+type Resize struct {
+	Width  int             // Target blocks, 0: by Height
+	Height int             // Target blocks, 0: by Width
+	Filter Filter          // Nearest, Box, Bilinear, Lanczos
+	Crop   image.Rectangle // Source pixel region from the top left, empty: whole image
+}
+
+// Cropped and resized image
+func (r Resize) Apply(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	if !r.Crop.Empty() {
+		crop := r.Crop.Add(bounds.Min).Intersect(bounds)
+		if crop.Empty() {
+			return nil, fmt.Errorf("crop %v is outside of image %dx%d", r.Crop, bounds.Dx(), bounds.Dy())
+		}
+		bounds = crop
+	}
+
+	w, h := bounds.Dx(), bounds.Dy()
+	width, height := w, h
+	switch {
+	case r.Width > 0 && r.Height > 0:
+		scale := math.Min(float64(r.Width)/float64(w), float64(r.Height)/float64(h))
+		width = max(1, int(math.Round(float64(w)*scale)))
+		height = max(1, int(math.Round(float64(h)*scale)))
+	case r.Width > 0:
+		width = r.Width
+		height = max(1, int(math.Round(float64(h)*float64(r.Width)/float64(w))))
+	case r.Height > 0:
+		height = r.Height
+		width = max(1, int(math.Round(float64(w)*float64(r.Height)/float64(h))))
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	if width == w && height == h {
+		return src, nil
+	}
+	return resample(src, width, height, r.Filter), nil
+}
+
+// Kernel and its radius in source pixels at scale 1
+func (f Filter) kernel() (kernel func(x float64) float64, support float64) {
+	switch f {
+	case Box:
+		return func(x float64) float64 {
+			if x >= -0.5 && x < 0.5 {
+				return 1
+			}
+			return 0
+		}, 0.5
+	case Bilinear:
+		return func(x float64) float64 {
+			return math.Max(0, 1-math.Abs(x))
+		}, 1
+	case Lanczos:
+		return func(x float64) float64 {
+			if x <= -3 || x >= 3 {
+				return 0
+			}
+			return sinc(x) * sinc(x/3)
+		}, 3
+	}
+	return nil, 0
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+type contribution struct {
+	index  []int
+	weight []float64
+}
+
+// Source pixels and weights of each destination pixel on one axis
+//
+// Kernel is widened by the scale when shrinking, so every source pixel contributes.
+func contributions(src, dst int, filter Filter) []contribution {
+	scale := float64(src) / float64(dst)
+	kernel, support := filter.kernel()
+	stretch := math.Max(scale, 1)
+	radius := support * stretch
+
+	list := make([]contribution, dst)
+	for i := range list {
+		center := (float64(i)+0.5)*scale - 0.5
+		if kernel == nil {
+			// Nearest
+			list[i] = contribution{index: []int{min(src-1, int((float64(i)+0.5)*scale))}, weight: []float64{1}}
+			continue
+		}
+
+		var c contribution
+		var sum float64
+		for j := int(math.Floor(center - radius)); j <= int(math.Ceil(center+radius)); j++ {
+			w := kernel((float64(j) - center) / stretch)
+			if w == 0 {
+				continue
+			}
+			c.index = append(c.index, max(0, min(src-1, j)))
+			c.weight = append(c.weight, w)
+			sum += w
+		}
+		if sum == 0 {
+			c = contribution{index: []int{max(0, min(src-1, int(math.Round(center))))}, weight: []float64{1}}
+			sum = 1
+		}
+		for k := range c.weight {
+			c.weight[k] /= sum
+		}
+		list[i] = c
+	}
+	return list
+}
+
+// Separable resample, horizontal then vertical
+func resample(src *image.NRGBA, width, height int, filter Filter) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Premultiplied alpha, so transparent pixels don't bleed their color
+	horizontal := make([][4]float64, width*h)
+	for i, c := range contributions(w, width, filter) {
+		for y := 0; y < h; y++ {
+			var sum [4]float64
+			for k, x := range c.index {
+				p := src.Pix[y*src.Stride+x*4:]
+				a := float64(p[3]) / 255
+				sum[0] += float64(p[0]) * a * c.weight[k]
+				sum[1] += float64(p[1]) * a * c.weight[k]
+				sum[2] += float64(p[2]) * a * c.weight[k]
+				sum[3] += a * c.weight[k]
+			}
+			horizontal[y*width+i] = sum
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for j, c := range contributions(h, height, filter) {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for k, y := range c.index {
+				v := horizontal[y*width+x]
+				for ch := range sum {
+					sum[ch] += v[ch] * c.weight[k]
+				}
+			}
+			p := dst.Pix[j*dst.Stride+x*4:]
+			a := math.Max(0, math.Min(1, sum[3]))
+			if a > 0 {
+				for ch := 0; ch < 3; ch++ {
+					p[ch] = uint8(math.Max(0, math.Min(255, math.Round(sum[ch]/a))))
+				}
+			}
+			p[3] = uint8(math.Round(a * 255))
+		}
+	}
+	return dst
+}
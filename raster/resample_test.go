@@ -0,0 +1,89 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func filled(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResizeSize(t *testing.T) {
+	img := filled(40, 20, color.NRGBA{A: 255})
+	tests := []struct {
+		resize Resize
+		w, h   int
+	}{
+		{Resize{}, 40, 20},
+		{Resize{Width: 10}, 10, 5},
+		{Resize{Height: 5}, 10, 5},
+		{Resize{Width: 10, Height: 10}, 10, 5}, // Fit inside
+		{Resize{Width: 100, Height: 10}, 20, 10},
+		{Resize{Crop: image.Rect(5, 5, 15, 10)}, 10, 5},
+		{Resize{Width: 2, Crop: image.Rect(30, 0, 50, 20)}, 2, 4}, // Crop is clipped to 10x20
+	}
+	for _, tt := range tests {
+		out, err := tt.resize.Apply(img)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.resize, err)
+		}
+		if b := out.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("%+v: %dx%d, want %dx%d", tt.resize, b.Dx(), b.Dy(), tt.w, tt.h)
+		}
+	}
+
+	if _, err := (Resize{Crop: image.Rect(50, 50, 60, 60)}).Apply(img); err == nil {
+		t.Error("crop outside of image: no error")
+	}
+}
+
+func TestResizeFilters(t *testing.T) {
+	// Left half black, right half white
+	img := filled(4, 4, color.NRGBA{A: 255})
+	for y := 0; y < 4; y++ {
+		for x := 2; x < 4; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	for _, filter := range []Filter{Nearest, Box, Bilinear, Lanczos} {
+		out, err := Resize{Width: 2, Filter: filter}.Apply(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		left := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA)
+		right := color.NRGBAModel.Convert(out.At(1, 1)).(color.NRGBA)
+		if left.R > 40 || right.R < 215 || left.A != 255 {
+			t.Errorf("filter %d: left %v right %v, want black and white halves", filter, left, right)
+		}
+	}
+
+	// Box of 4 pixels is their average
+	out, _ := Resize{Width: 1, Filter: Box}.Apply(img.SubImage(image.Rect(1, 0, 3, 2)))
+	if c := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA); c.R < 126 || c.R > 129 {
+		t.Errorf("box average %v, want gray", c)
+	}
+}
+
+func TestResizeTransparent(t *testing.T) {
+	// Transparent red pixels must not tint the opaque green ones
+	img := filled(2, 1, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 255, A: 0})
+
+	out, err := Resize{Width: 1, Filter: Bilinear}.Apply(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA)
+	if c.R != 0 || c.G != 255 || c.A < 120 || c.A > 135 {
+		t.Errorf("color %v, want green at half alpha", c)
+	}
+}
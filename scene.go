@@ -0,0 +1,159 @@
+package model2minecraft
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Source placed in a composed scene
+type SceneSource struct {
+	File      string         // .obj .stl .gltf .glb .vox .ply .xyz .png .jpg, or Minecraft model resource without extension
+	Configure func(*Options) // Options of this source, e.g. ObjectTransform, ObjectScale, AllowedBlockIds, nil: scene options
+	Offset    voxel.Pos      // Origin in the scene, blocks
+	Priority  int            // Higher wins overlapping blocks, same priority: later source
+}
+
+// Convert sources into one shared grid
+//
+// Sources are converted with their own options and placed by block position,
+// voxels of a source sharing a block are merged by its MergePolicy.
+// Averaged colors are matched to blocks again, LargestArea and OutwardNormal keep the first voxel
+// as areas and normals are gone after conversion.
+// Physics is applied once to the composed scene.
+func (c *Converter) ConvertScene(sources []SceneSource) (*VoxelSet, error) {
+	sceneStart := time.Now()
+	fmt.Fprintf(c.log, "\nScene compose start...\n")
+
+	order := make([]int, len(sources))
+	for i := range order {
+		order[i] = i
+	}
+	// Lower priority first, later writes win
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(sources[a].Priority, sources[b].Priority)
+	})
+
+	scene := c.newGrid(1.0)
+	for _, i := range order {
+		source := sources[i]
+		sc, err := c.sourceConverter(source.Configure)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.File, err)
+		}
+		grid, err := sc.ConvertFile(source.File)
+		if err != nil {
+			return nil, err
+		}
+		// Voxels sharing a block(ObjectGridSpacing < 1) are merged by the source MergePolicy
+		voxels := grid.Voxels()
+		positions := make([]voxel.Pos, len(voxels))
+		shared := map[voxel.Pos]int{}
+		for i, v := range voxels {
+			positions[i] = voxel.Pos{
+				X: int(math.Round(v.Position.X)) + source.Offset.X,
+				Y: int(math.Round(v.Position.Y)) + source.Offset.Y,
+				Z: int(math.Round(v.Position.Z)) + source.Offset.Z,
+			}
+			shared[positions[i]]++
+		}
+		// Averaged colors of shared blocks are matched again
+		average := sc.opt.MergePolicy == voxel.AverageColor || sc.opt.MergePolicy == voxel.LabAverage
+		layer := sc.newGrid(1.0)
+		for i, v := range voxels {
+			blockID := v.BlockID
+			if average && shared[positions[i]] > 1 {
+				blockID = ""
+			}
+			layer.Write(positions[i], v.Color, blockID)
+		}
+		layer.AssignBlocks(sc.matcher.Block)
+		layer.Each(func(v voxel.Voxel) {
+			scene.Put(v.Pos, v.Color, v.BlockID)
+		})
+		fmt.Fprintf(c.log, "Scene source %d: %s Voxel:%d Priority:%d\n", i+1, source.File, grid.Len(), source.Priority)
+	}
+	scene.ApplyPhysics(c.opt.PhysicsMode, c.opt.SupportBlockId, c.nearestSolidBlock)
+
+	w, h, d := scene.Size()
+	fmt.Fprintf(c.log, "\nScene compose duration: %s Voxel:%d W:%d H:%d D:%d\n", time.Since(sceneStart), scene.Len(), w, h, d)
+	return scene, nil
+}
+
+// Converter of a scene source, the palette is loaded again only when the block filter differs
+func (c *Converter) sourceConverter(configure func(*Options)) (*Converter, error) {
+	opt := c.opt
+	opt.AllowedBlockIds = slices.Clone(opt.AllowedBlockIds)
+	opt.IgnoredBlockIds = slices.Clone(opt.IgnoredBlockIds)
+	opt.BlockFilterPresets = slices.Clone(opt.BlockFilterPresets)
+	if configure != nil {
+		configure(&opt)
+	}
+	// Scene physics is applied after composition
+	opt.PhysicsMode = voxel.PhysicsIgnore
+
+	samePalette := opt.MinecraftDirectory == c.opt.MinecraftDirectory &&
+		slices.Equal(opt.AllowedBlockIds, c.opt.AllowedBlockIds) &&
+		slices.Equal(opt.IgnoredBlockIds, c.opt.IgnoredBlockIds) &&
+		slices.Equal(opt.BlockFilterPresets, c.opt.BlockFilterPresets) &&
+		opt.ColorDepthBit == c.opt.ColorDepthBit &&
+		reflect.ValueOf(opt.ColorMetric).Pointer() == reflect.ValueOf(c.opt.ColorMetric).Pointer()
+	if !samePalette {
+		sc, err := New(opt)
+		if err != nil {
+			return nil, err
+		}
+		sc.report = c.report
+		return sc, nil
+	}
+
+	if opt.ObjectGridSpacing <= 0 {
+		return nil, fmt.Errorf("object grid spacing must be positive: %f", opt.ObjectGridSpacing)
+	}
+	sc := *c
+	sc.opt = opt
+	if sc.opt.ParallelLimit <= 0 {
+		sc.opt.ParallelLimit = 1
+	}
+	return &sc, nil
+}
+
+// Convert file by extension, names without extension are Minecraft models
+func (c *Converter) ConvertFile(name string) (*VoxelSet, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return c.ConvertModel(name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext {
+	case ".obj":
+		return c.ConvertOBJ(f)
+	case ".stl":
+		return c.ConvertSTL(f)
+	case ".gltf", ".glb":
+		return c.ConvertGLTF(f)
+	case ".vox":
+		return c.ConvertVOX(f)
+	case ".ply":
+		return c.ConvertPLY(f)
+	case ".xyz":
+		return c.ConvertXYZ(f)
+	case ".png", ".jpg", ".jpeg":
+		return c.ConvertImage(f)
+	}
+	return nil, fmt.Errorf("%s: unsupported file type %q", name, ext)
+}
@@ -0,0 +1,101 @@
+package model2minecraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+func TestConvertSceneMergePolicy(t *testing.T) {
+	// Three points of spacing 0.25 share block 0: one red, two white
+	file := filepath.Join(t.TempDir(), "points.xyz")
+	if err := os.WriteFile(file, []byte("-0.25 0 0 255 0 0\n0 0 0 255 255 255\n0.25 0 0 255 255 255\n3 0 0 0 0 255\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy voxel.MergePolicy
+		want   string
+	}{
+		{voxel.FirstWins, "red_wool"},
+		{voxel.MajorityVote, "white_wool"},
+	}
+	for _, tt := range tests {
+		c := newTestConverter(t, nil)
+		scene, err := c.ConvertScene([]SceneSource{{
+			File: file,
+			Configure: func(opt *Options) {
+				opt.ObjectGridSpacing = 0.25
+				opt.MergePolicy = tt.policy
+			},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scene.Len() != 2 {
+			t.Fatalf("policy %d: %d blocks, want 2", tt.policy, scene.Len())
+		}
+		if v, _ := scene.Get(voxel.Pos{}); v.BlockID != tt.want {
+			t.Errorf("policy %d: block %q, want %q", tt.policy, v.BlockID, tt.want)
+		}
+	}
+}
+
+func TestConvertSceneAverageColor(t *testing.T) {
+	// Three red points and a blue point of spacing 0.25 share block 0
+	file := filepath.Join(t.TempDir(), "points.xyz")
+	if err := os.WriteFile(file, []byte("-0.25 0 0 255 0 0\n0 0 0 255 0 0\n0.25 0 0 255 0 0\n0 0.25 0 0 0 255\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy voxel.MergePolicy
+		want   string
+	}{
+		{voxel.LastWins, "blue_wool"},
+		// (191, 0, 64) is matched again instead of keeping blue_wool of the last voxel
+		{voxel.AverageColor, "red_wool"},
+		{voxel.LabAverage, "red_wool"},
+	}
+	for _, tt := range tests {
+		c := newTestConverter(t, nil)
+		scene, err := c.ConvertScene([]SceneSource{{
+			File: file,
+			Configure: func(opt *Options) {
+				opt.ObjectGridSpacing = 0.25
+				opt.MergePolicy = tt.policy
+			},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := scene.Get(voxel.Pos{})
+		if scene.Len() != 1 || v.BlockID != tt.want {
+			t.Errorf("policy %d: %d blocks, block %q, want 1 %q", tt.policy, scene.Len(), v.BlockID, tt.want)
+		}
+	}
+}
+
+func TestConvertScenePriority(t *testing.T) {
+	dir := t.TempDir()
+	red, blue := filepath.Join(dir, "red.xyz"), filepath.Join(dir, "blue.xyz")
+	os.WriteFile(red, []byte("0 0 0 255 0 0\n1 0 0 255 0 0\n"), 0o644)
+	os.WriteFile(blue, []byte("0 0 0 0 0 255\n"), 0o644)
+
+	c := newTestConverter(t, nil)
+	scene, err := c.ConvertScene([]SceneSource{
+		{File: red, Priority: 1},
+		{File: blue, Offset: voxel.Pos{X: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := scene.Get(voxel.Pos{X: 1}); v.BlockID != "red_wool" {
+		t.Errorf("overlapping block %q, want red_wool of higher priority", v.BlockID)
+	}
+	if scene.Len() != 2 {
+		t.Errorf("%d blocks, want 2", scene.Len())
+	}
+}
@@ -0,0 +1,110 @@
+package model2minecraft
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatomu/model2minecraft/raster"
+)
+
+var durationPattern = regexp.MustCompile(".*([0-9]{2}):([0-9]{2}):([0-9]{2}).*")
+
+// Convert video frames at VideoFrameRate (*requires ffmpeg)
+//
+// Failed frames are reported and kept as empty set to keep playback timing.
+func (c *Converter) ConvertVideo(videoFilename string) ([]*VoxelSet, error) {
+	videoStart := time.Now()
+	fmt.Fprintf(c.log, "\nVideo parse start...\n")
+	if c.opt.VideoFrameRate <= 0 {
+		return nil, fmt.Errorf("video frame rate must be positive: %d", c.opt.VideoFrameRate)
+	}
+
+	// Get video duration
+	if _, err := os.Stat(videoFilename); err != nil {
+		return nil, err
+	}
+	// ffmpeg exits with 1 without output file, only the output is used
+	out, err := exec.Command("ffmpeg", "-i", videoFilename).CombinedOutput()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+	var duration float64
+	for _, line := range strings.Split(string(out), "\n") {
+		// 動画時間入手
+		if strings.Contains(line, "Duration") {
+			line = durationPattern.ReplaceAllString(line, "$1 $2 $3")
+			var hour, min, sec int
+			fmt.Sscanf(line, "%d %d %d", &hour, &min, &sec)
+			duration = float64(hour*3600 + min*60 + sec - 1)
+			break
+		}
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("%s: video duration not found in ffmpeg output", videoFilename)
+	}
+	fmt.Fprintf(c.log, "Video duration: %f\n", duration)
+
+	var times []float64
+	for current := 0.0; current < duration; current += 1.0 / float64(c.opt.VideoFrameRate) {
+		times = append(times, current)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		session = make(chan struct{}, c.opt.ParallelLimit)
+		frames  = make([]*VoxelSet, len(times))
+	)
+	for i, current := range times {
+		frame := i + 1
+
+		session <- struct{}{}
+		wg.Add(1)
+		go func(fCurrent float64, fFrame int) {
+			defer func() {
+				<-session
+				wg.Done()
+			}()
+
+			fmt.Fprintf(c.log, "Start: frame: %d(%5.2f/%5.2f)\n", fFrame, fCurrent, duration)
+			execute := exec.Command("ffmpeg", "-i", videoFilename, "-ss", fmt.Sprintf("%.3f", fCurrent), "-frames:v", "1", "-vf", "scale="+c.opt.VideoScaleSize, "-f", "image2pipe", "-vcodec", "png", "pipe:1")
+
+			var buf, stderr bytes.Buffer
+			execute.Stdout = &buf
+			execute.Stderr = &stderr
+			if err := execute.Run(); err != nil {
+				c.report.Add("video", videoFilename, 0, fmt.Errorf("frame %d(%.3fs): ffmpeg: %w: %s", fFrame, fCurrent, err, lastLine(stderr.String())))
+				return
+			}
+			pixels, err := raster.Parse(&buf)
+			if err != nil {
+				c.report.Add("video", videoFilename, 0, fmt.Errorf("frame %d(%.3fs): %w", fFrame, fCurrent, err))
+				return
+			}
+
+			frames[fFrame-1] = c.pixelsToVoxels(pixels)
+
+			fmt.Fprintf(c.log, "Finish: frame: %d(%5.2f/%5.2f)\n", fFrame, fCurrent, duration)
+		}(current, frame)
+	}
+	wg.Wait()
+
+	for i := range frames {
+		if frames[i] == nil {
+			frames[i] = c.newGrid(1.0)
+		}
+	}
+
+	fmt.Fprintf(c.log, "\nFrame to function duration: %s, Frame: %d\n", time.Since(videoStart), len(frames))
+	return frames, nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,44 @@
+package model2minecraft
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aatomu/model2minecraft/vox"
+	"github.com/aatomu/model2minecraft/voxel"
+)
+
+// Convert MagicaVoxel .vox, each voxel becomes exactly one block
+//
+// Palette colors are matched once per index, VoxBlockIds overrides them.
+func (c *Converter) ConvertVOX(r io.Reader) (*VoxelSet, error) {
+	voxStart := time.Now()
+	fmt.Fprintf(c.log, "\nVOX parse start...\n")
+
+	scene, err := vox.Parse(r, sourceName(r, "model.vox"), c.report)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks [256]string
+	for index, color := range scene.Palette {
+		blocks[index] = c.matcher.Block(color)
+	}
+	for index, id := range c.opt.VoxBlockIds {
+		if index < 1 || index > 255 {
+			return nil, fmt.Errorf("%s: palette index must be 1-255: %d", scene.Name, index)
+		}
+		blocks[index] = id
+	}
+
+	grid := c.newGrid(1.0)
+	for _, v := range scene.Voxels {
+		grid.Write(voxel.Pos{X: v.X, Y: v.Y, Z: v.Z}, scene.Palette[v.Index], blocks[v.Index])
+	}
+	c.finalize(grid)
+
+	w, h, d := grid.Size()
+	fmt.Fprintf(c.log, "\nVOX parse duration: %s Voxel:%d W:%d H:%d D:%d\n", time.Since(voxStart), grid.Len(), w, h, d)
+	return grid, nil
+}
@@ -0,0 +1,86 @@
+package vox
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Little endian chunk content reader, keeps the first error
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of chunk")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) int() int {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+func (r *reader) string() string {
+	return string(r.bytes(r.int()))
+}
+
+func (r *reader) dict() map[string]string {
+	d := map[string]string{}
+	n := r.int()
+	for i := 0; i < n && r.err == nil; i++ {
+		key := r.string()
+		d[key] = r.string()
+	}
+	return d
+}
+
+// MagicaVoxel palette used when RGBA chunk is missing
+func defaultPalette() (p [256]palette.Color) {
+	levels := []uint8{0xff, 0xcc, 0x99, 0x66, 0x33, 0x00}
+	i := 1
+	// 6x6x6 color cube without black
+	for _, r := range levels {
+		for _, g := range levels {
+			for _, b := range levels {
+				if i > 215 {
+					break
+				}
+				p[i] = palette.Color{R: r, G: g, B: b}
+				i++
+			}
+		}
+	}
+	// Red, green, blue and gray ramps
+	ramp := []uint8{0xee, 0xdd, 0xbb, 0xaa, 0x88, 0x77, 0x55, 0x44, 0x22, 0x11}
+	for channel := 0; channel < 4; channel++ {
+		for _, v := range ramp {
+			switch channel {
+			case 0:
+				p[i] = palette.Color{R: v}
+			case 1:
+				p[i] = palette.Color{G: v}
+			case 2:
+				p[i] = palette.Color{B: v}
+			case 3:
+				p[i] = palette.Color{R: v, G: v, B: v}
+			}
+			i++
+		}
+	}
+	return
+}
@@ -0,0 +1,273 @@
+// Package vox reads MagicaVoxel .vox scenes.
+package vox
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aatomu/model2minecraft/diagnostic"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Voxel in Minecraft axes(Y+ is up)
+type Voxel struct {
+	X, Y, Z int
+	Index   uint8 // Palette index 1-255
+}
+
+type Scene struct {
+	Name    string
+	Voxels  []Voxel
+	Palette [256]palette.Color // Palette[index], index 0 is empty
+}
+
+type model struct {
+	size   [3]int
+	voxels [][4]uint8 // x, y, z, index
+}
+
+type node struct {
+	kind     string // nTRN, nGRP, nSHP
+	attr     map[string]string
+	child    int
+	layer    int
+	frame    map[string]string
+	children []int
+	models   []int
+}
+
+type parser struct {
+	name   string
+	report *diagnostic.Report
+	models []model
+	nodes  map[int]*node
+	hidden map[int]bool // Hidden layers
+	scene  *Scene
+}
+
+// Parse .vox with SIZE/XYZI/RGBA chunks and the nTRN/nGRP/nSHP scene graph
+//
+// Hidden nodes and layers are skipped. Without scene graph, models are placed at the origin.
+func Parse(r io.Reader, name string, report *diagnostic.Report) (*Scene, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 || string(data[:4]) != "VOX " {
+		return nil, fmt.Errorf("%s: not a .vox file", name)
+	}
+
+	p := &parser{
+		name:   name,
+		report: report,
+		nodes:  map[int]*node{},
+		hidden: map[int]bool{},
+		scene:  &Scene{Name: name, Palette: defaultPalette()},
+	}
+	if err := p.parseChunks(data[8:]); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(p.models) == 0 {
+		return nil, fmt.Errorf("%s: no model found", name)
+	}
+
+	if _, ok := p.nodes[0]; ok {
+		p.walk(0, identity, [3]int{}, 0)
+	} else {
+		for i := range p.models {
+			p.place(i, identity, [3]int{})
+		}
+	}
+	return p.scene, nil
+}
+
+func (p *parser) parseChunks(data []byte) error {
+	var sizes [][3]int
+	for offset := 0; offset < len(data); {
+		if offset+12 > len(data) {
+			return fmt.Errorf("broken chunk header at %d", offset)
+		}
+		id := string(data[offset : offset+4])
+		contentSize := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		childrenSize := int(binary.LittleEndian.Uint32(data[offset+8:]))
+		start := offset + 12
+		if contentSize < 0 || start+contentSize > len(data) {
+			return fmt.Errorf("chunk %q exceeds file", id)
+		}
+		c := &reader{data: data[start : start+contentSize]}
+
+		switch id {
+		case "MAIN":
+			// Children follow as next chunks
+			offset = start + contentSize
+			continue
+		case "SIZE":
+			sizes = append(sizes, [3]int{c.int(), c.int(), c.int()})
+		case "XYZI":
+			if len(sizes) == 0 {
+				return fmt.Errorf("XYZI without SIZE")
+			}
+			n := c.int()
+			m := model{size: sizes[len(sizes)-1]}
+			for i := 0; i < n && c.err == nil; i++ {
+				b := c.bytes(4)
+				if c.err == nil {
+					m.voxels = append(m.voxels, [4]uint8{b[0], b[1], b[2], b[3]})
+				}
+			}
+			p.models = append(p.models, m)
+		case "RGBA":
+			for i := 1; i < 256 && c.err == nil; i++ {
+				b := c.bytes(4)
+				if c.err == nil {
+					p.scene.Palette[i] = palette.Color{R: b[0], G: b[1], B: b[2]}
+				}
+			}
+		case "nTRN":
+			id := c.int()
+			n := &node{kind: "nTRN", attr: c.dict(), child: c.int()}
+			c.int() // reserved
+			n.layer = c.int()
+			if frames := c.int(); frames > 0 {
+				n.frame = c.dict()
+			}
+			p.nodes[id] = n
+		case "nGRP":
+			id := c.int()
+			n := &node{kind: "nGRP", attr: c.dict()}
+			count := c.int()
+			for i := 0; i < count && c.err == nil; i++ {
+				n.children = append(n.children, c.int())
+			}
+			p.nodes[id] = n
+		case "nSHP":
+			id := c.int()
+			n := &node{kind: "nSHP", attr: c.dict()}
+			count := c.int()
+			for i := 0; i < count && c.err == nil; i++ {
+				n.models = append(n.models, c.int())
+				c.dict()
+			}
+			p.nodes[id] = n
+		case "LAYR":
+			id := c.int()
+			if c.dict()["_hidden"] == "1" {
+				p.hidden[id] = true
+			}
+		}
+		if c.err != nil {
+			p.report.Add("vox", p.name, 0, fmt.Errorf("chunk %q: %w", id, c.err))
+		}
+		offset = start + contentSize + childrenSize
+	}
+	return nil
+}
+
+// Rotation matrix rows of vox space
+type matrix [3][3]int
+
+var identity = matrix{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+func (a matrix) mul(b matrix) (m matrix) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return
+}
+
+func (a matrix) apply(v [3]int) (r [3]int) {
+	for i := 0; i < 3; i++ {
+		r[i] = a[i][0]*v[0] + a[i][1]*v[1] + a[i][2]*v[2]
+	}
+	return
+}
+
+// _r packed rotation: bit0-1 first row index, bit2-3 second row index, bit4-6 row signs
+func parseRotation(s string) (m matrix, err error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return identity, err
+	}
+	first, second := v&3, (v>>2)&3
+	if first > 2 || second > 2 || first == second {
+		return identity, fmt.Errorf("invalid rotation %d", v)
+	}
+	third := 3 - first - second
+	for row, index := range [3]int{first, second, third} {
+		m[row][index] = 1
+		if v>>(4+row)&1 == 1 {
+			m[row][index] = -1
+		}
+	}
+	return m, nil
+}
+
+func (p *parser) walk(id int, rotation matrix, translation [3]int, depth int) {
+	n, ok := p.nodes[id]
+	if !ok || depth > 256 {
+		p.report.Add("vox", p.name, 0, fmt.Errorf("node %d: broken scene graph", id))
+		return
+	}
+	if n.attr["_hidden"] == "1" {
+		return
+	}
+
+	switch n.kind {
+	case "nTRN":
+		if p.hidden[n.layer] || n.frame["_hidden"] == "1" {
+			return
+		}
+		local := identity
+		if r, ok := n.frame["_r"]; ok {
+			var err error
+			if local, err = parseRotation(r); err != nil {
+				p.report.Add("vox", p.name, 0, fmt.Errorf("node %d: %w", id, err))
+			}
+		}
+		var t [3]int
+		if s, ok := n.frame["_t"]; ok {
+			fields := strings.Fields(s)
+			for i := 0; i < 3 && i < len(fields); i++ {
+				t[i], _ = strconv.Atoi(fields[i])
+			}
+		}
+		// parent(local(v)) = R*(r*v + t) + T
+		moved := rotation.apply(t)
+		for i := range moved {
+			moved[i] += translation[i]
+		}
+		p.walk(n.child, rotation.mul(local), moved, depth+1)
+	case "nGRP":
+		for _, child := range n.children {
+			p.walk(child, rotation, translation, depth+1)
+		}
+	case "nSHP":
+		for _, m := range n.models {
+			p.place(m, rotation, translation)
+		}
+	}
+}
+
+// Place model centered at translation, Z-up vox to Y-up Minecraft
+func (p *parser) place(index int, rotation matrix, translation [3]int) {
+	if index < 0 || index >= len(p.models) {
+		p.report.Add("vox", p.name, 0, fmt.Errorf("model %d out of range", index))
+		return
+	}
+	m := p.models[index]
+	for _, v := range m.voxels {
+		// Pivot is size/2
+		w := rotation.apply([3]int{int(v[0]) - m.size[0]/2, int(v[1]) - m.size[1]/2, int(v[2]) - m.size[2]/2})
+		for i := range w {
+			w[i] += translation[i]
+		}
+		p.scene.Voxels = append(p.scene.Voxels, Voxel{X: w[0], Y: w[2], Z: -w[1], Index: v[3]})
+	}
+}
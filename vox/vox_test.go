@@ -0,0 +1,110 @@
+package vox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func chunk(id string, content ...any) []byte {
+	var c bytes.Buffer
+	for _, v := range content {
+		switch v := v.(type) {
+		case string:
+			binary.Write(&c, binary.LittleEndian, int32(len(v)))
+			c.WriteString(v)
+		case int:
+			binary.Write(&c, binary.LittleEndian, int32(v))
+		default:
+			binary.Write(&c, binary.LittleEndian, v)
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString(id)
+	binary.Write(&b, binary.LittleEndian, int32(c.Len()))
+	binary.Write(&b, binary.LittleEndian, int32(0))
+	b.Write(c.Bytes())
+	return b.Bytes()
+}
+
+func voxFile(chunks ...[]byte) []byte {
+	children := bytes.Join(chunks, nil)
+	var b bytes.Buffer
+	b.WriteString("VOX ")
+	binary.Write(&b, binary.LittleEndian, int32(150))
+	b.WriteString("MAIN")
+	binary.Write(&b, binary.LittleEndian, int32(0))
+	binary.Write(&b, binary.LittleEndian, int32(len(children)))
+	b.Write(children)
+	return b.Bytes()
+}
+
+func TestParseModel(t *testing.T) {
+	rgba := make([]byte, 256*4)
+	copy(rgba, []byte{255, 0, 0, 255})
+	data := voxFile(
+		chunk("SIZE", 2, 2, 2),
+		chunk("XYZI", 2, []byte{0, 0, 0, 1, 1, 1, 1, 1}),
+		chunk("RGBA", rgba),
+	)
+	scene, err := Parse(bytes.NewReader(data), "model.vox", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pivot is size/2, vox Z is Minecraft Y, vox Y is Minecraft -Z
+	want := []Voxel{{X: -1, Y: -1, Z: 1, Index: 1}, {X: 0, Y: 0, Z: 0, Index: 1}}
+	if len(scene.Voxels) != len(want) {
+		t.Fatalf("voxels %v, want %v", scene.Voxels, want)
+	}
+	for i := range want {
+		if scene.Voxels[i] != want[i] {
+			t.Errorf("voxel %d: %v, want %v", i, scene.Voxels[i], want[i])
+		}
+	}
+	if scene.Palette[1] != (palette.Color{R: 255}) {
+		t.Errorf("palette 1: %v, want red", scene.Palette[1])
+	}
+}
+
+func TestParseSceneGraph(t *testing.T) {
+	model := []any{chunk("SIZE", 1, 1, 1), chunk("XYZI", 1, []byte{0, 0, 0, 1})}
+	data := voxFile(
+		model[0].([]byte), model[1].([]byte),
+		// nTRN 0 -> nGRP 1 -> nTRN 2(moved) -> nSHP 3, nTRN 4(hidden layer) -> nSHP 3
+		chunk("nTRN", 0, 0, 1, -1, 0, 1, 0),
+		chunk("nGRP", 1, 0, 2, 2, 4),
+		chunk("nTRN", 2, 0, 3, -1, 0, 1, 2, "_t", "5 6 7", "_r", "20"),
+		chunk("nSHP", 3, 0, 1, 0, 0),
+		chunk("nTRN", 4, 0, 3, -1, 1, 1, 0),
+		chunk("LAYR", 1, 1, "_hidden", "1", -1),
+	)
+	scene, err := Parse(bytes.NewReader(data), "scene.vox", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Voxel{X: 5, Y: 7, Z: -6, Index: 1}
+	if len(scene.Voxels) != 1 || scene.Voxels[0] != want {
+		t.Errorf("voxels %v, want [%v]", scene.Voxels, want)
+	}
+}
+
+func TestParseRotation(t *testing.T) {
+	// 0b0100: rows x, y, z without sign
+	if m, err := parseRotation("4"); err != nil || m != identity {
+		t.Errorf("rotation 4: %v %v, want identity", m, err)
+	}
+	// Row 1 from y negated, row 2 from x, row 3 from z: 90 degrees around z
+	m, err := parseRotation("17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := m.apply([3]int{1, 0, 0}); v != [3]int{0, 1, 0} {
+		t.Errorf("rotated x %v, want [0 1 0]", v)
+	}
+	if _, err := parseRotation("3"); err == nil {
+		t.Error("rotation 3: no error")
+	}
+}
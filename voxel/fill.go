@@ -0,0 +1,129 @@
+package voxel
+
+import (
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Interior voxelization method
+type FillMode int
+
+const (
+	Hollow    FillMode = iota // Surface only
+	FloodFill                 // Empty voxels not reachable from outside the bounds
+	Parity                    // Ray casting parity on mesh(needs watertight mesh)
+)
+
+type FillOptions struct {
+	BlockID        string // "": nearest surface color
+	ShellThickness int    // Filled depth from surface, 0: fill all
+}
+
+// Dense bit set over a box
+type box struct {
+	lo, hi Pos
+	w, h   int
+	bits   []uint64
+}
+
+func newBox(lo, hi Pos) *box {
+	w, h, d := hi.X-lo.X+1, hi.Y-lo.Y+1, hi.Z-lo.Z+1
+	return &box{lo: lo, hi: hi, w: w, h: h, bits: make([]uint64, (w*h*d+63)/64)}
+}
+
+func (b *box) contains(p Pos) bool {
+	return p.X >= b.lo.X && p.Y >= b.lo.Y && p.Z >= b.lo.Z && p.X <= b.hi.X && p.Y <= b.hi.Y && p.Z <= b.hi.Z
+}
+
+func (b *box) index(p Pos) int {
+	return (p.Z-b.lo.Z)*b.w*b.h + (p.Y-b.lo.Y)*b.w + (p.X - b.lo.X)
+}
+
+func (b *box) get(p Pos) bool {
+	i := b.index(p)
+	return b.bits[i/64]&(1<<(i%64)) != 0
+}
+
+func (b *box) set(p Pos) {
+	i := b.index(p)
+	b.bits[i/64] |= 1 << (i % 64)
+}
+
+var neighbors6 = [6]Pos{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+
+// Empty voxels enclosed by the surface, found by flood fill from outside the bounds
+func (g *Grid) FloodInterior() func(p Pos) bool {
+	if g.count == 0 {
+		return func(p Pos) bool { return false }
+	}
+	lo, hi := g.Bounds()
+	outside := newBox(lo.Add(-1, -1, -1), hi.Add(1, 1, 1))
+
+	queue := []Pos{outside.lo}
+	outside.set(outside.lo)
+	for len(queue) > 0 {
+		p := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, n := range neighbors6 {
+			next := p.Add(n.X, n.Y, n.Z)
+			if !outside.contains(next) || outside.get(next) || g.Has(next) {
+				continue
+			}
+			outside.set(next)
+			queue = append(queue, next)
+		}
+	}
+
+	return func(p Pos) bool {
+		return outside.contains(p) && !outside.get(p)
+	}
+}
+
+// Fill empty voxels inside the bounds where inside(p) is true.
+//
+// Color comes from the nearest surface voxel(6-connected distance).
+func (g *Grid) FillInterior(inside func(p Pos) bool, opt FillOptions) (filled int) {
+	if g.count == 0 {
+		return 0
+	}
+	lo, hi := g.Bounds()
+	visited := newBox(lo, hi)
+
+	type item struct {
+		pos   Pos
+		color palette.Color
+		depth int
+	}
+
+	// Interior voxels next to surface
+	var queue []item
+	for _, v := range g.Voxels() {
+		for _, n := range neighbors6 {
+			next := v.Pos.Add(n.X, n.Y, n.Z)
+			if !visited.contains(next) || visited.get(next) || g.Has(next) || !inside(next) {
+				continue
+			}
+			visited.set(next)
+			queue = append(queue, item{pos: next, color: v.Color, depth: 1})
+		}
+	}
+
+	// Breadth first, so nearest surface color reaches first
+	for head := 0; head < len(queue); head++ {
+		it := queue[head]
+		if opt.ShellThickness > 0 && it.depth > opt.ShellThickness {
+			continue
+		}
+		g.Put(it.pos, it.color, opt.BlockID)
+		filled++
+
+		for _, n := range neighbors6 {
+			next := it.pos.Add(n.X, n.Y, n.Z)
+			if !visited.contains(next) || visited.get(next) || g.Has(next) || !inside(next) {
+				continue
+			}
+			visited.set(next)
+			queue = append(queue, item{pos: next, color: it.color, depth: it.depth + 1})
+		}
+	}
+	return
+}
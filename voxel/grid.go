@@ -0,0 +1,269 @@
+// Package voxel holds the converted blocks passed from sources to exporters.
+package voxel
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+const (
+	sectionBits = 4
+	sectionSize = 1 << sectionBits // 16³ blocks per section
+	sectionMask = sectionSize - 1
+)
+
+// Grid coordinate
+type Pos struct {
+	X, Y, Z int
+}
+
+func (p Pos) Add(x, y, z int) Pos {
+	return Pos{p.X + x, p.Y + y, p.Z + z}
+}
+
+// World position
+type Position struct {
+	X, Y, Z float64
+}
+
+type Voxel struct {
+	Pos      Pos           // Grid coordinate
+	Position Position      // World position: Pos * Grid.Spacing
+	Color    palette.Color // Source color
+	BlockID  string
+}
+
+// Sparse voxel grid chunked by 16³ sections
+type Grid struct {
+	Spacing  float64 // World size of a voxel
+	Policy   MergePolicy
+	Vote     func(c palette.Color) string // MajorityVote key of samples without block id, nil: color
+	sections map[Pos]*section             // key: section coordinate
+	count    int
+}
+
+type section struct {
+	index  [sectionSize * sectionSize * sectionSize]uint16 // 0: empty, n: cells[n-1]
+	cells  []cell
+	locals []uint16 // index position of cells[n]
+}
+
+func NewGrid(spacing float64, policy MergePolicy) *Grid {
+	return &Grid{
+		Spacing:  spacing,
+		Policy:   policy,
+		sections: map[Pos]*section{},
+	}
+}
+
+func splitPos(p Pos) (sectionPos Pos, local int) {
+	sectionPos = Pos{p.X >> sectionBits, p.Y >> sectionBits, p.Z >> sectionBits}
+	local = (p.Y&sectionMask)<<(2*sectionBits) | (p.Z&sectionMask)<<sectionBits | p.X&sectionMask
+	return
+}
+
+// Nearest grid coordinate of world position
+func (g *Grid) ToPos(p Position) Pos {
+	return Pos{
+		X: int(math.Round(p.X / g.Spacing)),
+		Y: int(math.Round(p.Y / g.Spacing)),
+		Z: int(math.Round(p.Z / g.Spacing)),
+	}
+}
+
+func (g *Grid) ToPosition(p Pos) Position {
+	return Position{
+		X: float64(p.X) * g.Spacing,
+		Y: float64(p.Y) * g.Spacing,
+		Z: float64(p.Z) * g.Spacing,
+	}
+}
+
+func (g *Grid) lookup(p Pos, create bool) *cell {
+	sectionPos, local := splitPos(p)
+	s, ok := g.sections[sectionPos]
+	if !ok {
+		if !create {
+			return nil
+		}
+		s = &section{}
+		g.sections[sectionPos] = s
+	}
+	if i := s.index[local]; i > 0 {
+		return &s.cells[i-1]
+	}
+	if !create {
+		return nil
+	}
+	s.cells = append(s.cells, cell{})
+	s.locals = append(s.locals, uint16(local))
+	s.index[local] = uint16(len(s.cells))
+	g.count++
+	return &s.cells[len(s.cells)-1]
+}
+
+// Write a sample, overlapping writes are merged by Policy.
+//
+// blockID may be empty, it is assigned later from the merged color.
+func (g *Grid) Write(p Pos, color palette.Color, blockID string) {
+	g.lookup(p, true).write(g.Policy, Sample{Color: color, BlockID: blockID}, g.Vote)
+}
+
+// Write a surface sample at its world position
+func (g *Grid) WriteSample(s Sample) {
+	g.lookup(g.ToPos(s.Position), true).write(g.Policy, s, g.Vote)
+}
+
+// Write a sample at world position
+func (g *Grid) WriteAt(p Position, color palette.Color, blockID string) {
+	g.Write(g.ToPos(p), color, blockID)
+}
+
+// Overwrite a voxel ignoring Policy
+func (g *Grid) Put(p Pos, color palette.Color, blockID string) {
+	c := g.lookup(p, true)
+	*c = cell{}
+	c.write(LastWins, Sample{Color: color, BlockID: blockID}, nil)
+}
+
+// Set block id of existing voxel
+func (g *Grid) SetBlock(p Pos, blockID string) bool {
+	c := g.lookup(p, false)
+	if c == nil {
+		return false
+	}
+	c.blockID = blockID
+	return true
+}
+
+func (g *Grid) Get(p Pos) (v Voxel, ok bool) {
+	c := g.lookup(p, false)
+	if c == nil {
+		return v, false
+	}
+	return g.voxel(p, c), true
+}
+
+func (g *Grid) Has(p Pos) bool {
+	return g.lookup(p, false) != nil
+}
+
+func (g *Grid) Delete(p Pos) {
+	sectionPos, local := splitPos(p)
+	s, ok := g.sections[sectionPos]
+	if !ok || s.index[local] == 0 {
+		return
+	}
+	// Move last cell into the hole
+	i := s.index[local] - 1
+	last := uint16(len(s.cells) - 1)
+	if i != last {
+		s.cells[i] = s.cells[last]
+		s.locals[i] = s.locals[last]
+		s.index[s.locals[i]] = i + 1
+	}
+	s.cells = s.cells[:last]
+	s.locals = s.locals[:last]
+	s.index[local] = 0
+	g.count--
+	if len(s.cells) == 0 {
+		delete(g.sections, sectionPos)
+	}
+}
+
+func (g *Grid) Len() int {
+	return g.count
+}
+
+func (g *Grid) voxel(p Pos, c *cell) Voxel {
+	return Voxel{
+		Pos:      p,
+		Position: g.ToPosition(p),
+		Color:    c.color,
+		BlockID:  c.blockID,
+	}
+}
+
+// Call fn for every voxel in no particular order
+func (g *Grid) Each(fn func(v Voxel)) {
+	for sectionPos, s := range g.sections {
+		base := Pos{sectionPos.X << sectionBits, sectionPos.Y << sectionBits, sectionPos.Z << sectionBits}
+		for local, i := range s.index {
+			if i == 0 {
+				continue
+			}
+			p := base.Add(local&sectionMask, local>>(2*sectionBits), (local>>sectionBits)&sectionMask)
+			fn(g.voxel(p, &s.cells[i-1]))
+		}
+	}
+}
+
+// All voxels sorted by y, x, z
+func (g *Grid) Voxels() []Voxel {
+	voxels := make([]Voxel, 0, g.count)
+	g.Each(func(v Voxel) {
+		voxels = append(voxels, v)
+	})
+	slices.SortFunc(voxels, func(a, b Voxel) int {
+		return ComparePos(a.Pos, b.Pos)
+	})
+	return voxels
+}
+
+// Order by y, x, z
+func ComparePos(a, b Pos) int {
+	if c := cmp.Compare(a.Y, b.Y); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.X, b.X); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Z, b.Z)
+}
+
+// Set block id of every voxel from its merged color
+func (g *Grid) AssignBlocks(block func(c palette.Color) string) {
+	for _, s := range g.sections {
+		for i := range s.cells {
+			if s.cells[i].blockID == "" {
+				s.cells[i].blockID = block(s.cells[i].color)
+			}
+		}
+	}
+}
+
+// Min/Max grid coordinate
+func (g *Grid) Bounds() (lo, hi Pos) {
+	first := true
+	g.Each(func(v Voxel) {
+		if first {
+			lo, hi = v.Pos, v.Pos
+			first = false
+			return
+		}
+		lo = Pos{X: min(lo.X, v.Pos.X), Y: min(lo.Y, v.Pos.Y), Z: min(lo.Z, v.Pos.Z)}
+		hi = Pos{X: max(hi.X, v.Pos.X), Y: max(hi.Y, v.Pos.Y), Z: max(hi.Z, v.Pos.Z)}
+	})
+	return
+}
+
+// Bounds size in blocks
+func (g *Grid) Size() (w, h, d int) {
+	if g.count == 0 {
+		return 0, 0, 0
+	}
+	lo, hi := g.Bounds()
+	return hi.X - lo.X + 1, hi.Y - lo.Y + 1, hi.Z - lo.Z + 1
+}
+
+// map[blockID]count
+func (g *Grid) UsedBlocks() map[string]int {
+	used := map[string]int{}
+	g.Each(func(v Voxel) {
+		used[v.BlockID]++
+	})
+	return used
+}
@@ -0,0 +1,43 @@
+package voxel
+
+// Delete voxels whose 6 neighbors are all occupied, only the visible shell is kept
+func (g *Grid) RemoveHidden() (removed int) {
+	var hidden []Pos
+	g.Each(func(v Voxel) {
+		for _, n := range neighbors6 {
+			if !g.Has(v.Pos.Add(n.X, n.Y, n.Z)) {
+				return
+			}
+		}
+		hidden = append(hidden, v.Pos)
+	})
+	for _, p := range hidden {
+		g.Delete(p)
+	}
+	return len(hidden)
+}
+
+// Shrink by integer factor, factor³ voxels are merged into one by Policy
+//
+// Spacing is kept, so the model becomes 1/factor size.
+func (g *Grid) Downsample(factor int) *Grid {
+	grid := NewGrid(g.Spacing, g.Policy)
+	grid.Vote = g.Vote
+	if g.Policy == OutwardNormal {
+		// Merged voxels have no normal, keep the first
+		grid.Policy = FirstWins
+	}
+	for _, v := range g.Voxels() {
+		grid.Write(Pos{floorDiv(v.Pos.X, factor), floorDiv(v.Pos.Y, factor), floorDiv(v.Pos.Z, factor)}, v.Color, v.BlockID)
+	}
+	grid.Policy = g.Policy
+	return grid
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,79 @@
+package voxel
+
+import (
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func cube(size int) *Grid {
+	g := NewGrid(1, FirstWins)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			for z := 0; z < size; z++ {
+				g.Write(Pos{x, y, z}, palette.Color{R: uint8(x), G: uint8(y), B: uint8(z)}, "")
+			}
+		}
+	}
+	return g
+}
+
+func TestRemoveHidden(t *testing.T) {
+	g := cube(20)
+	if removed := g.RemoveHidden(); removed != 18*18*18 {
+		t.Fatalf("removed %d, want %d", removed, 18*18*18)
+	}
+	if g.Len() != 20*20*20-18*18*18 {
+		t.Fatalf("len %d, want %d", g.Len(), 20*20*20-18*18*18)
+	}
+
+	// Moved cells keep their own position and color
+	count := 0
+	g.Each(func(v Voxel) {
+		count++
+		if v.Color != (palette.Color{R: uint8(v.Pos.X), G: uint8(v.Pos.Y), B: uint8(v.Pos.Z)}) {
+			t.Errorf("voxel %v has color %v", v.Pos, v.Color)
+		}
+		if v.Pos.X > 0 && v.Pos.X < 19 && v.Pos.Y > 0 && v.Pos.Y < 19 && v.Pos.Z > 0 && v.Pos.Z < 19 {
+			t.Errorf("hidden voxel %v is kept", v.Pos)
+		}
+	})
+	if count != g.Len() {
+		t.Errorf("each %d, len %d", count, g.Len())
+	}
+}
+
+func TestDeleteReuse(t *testing.T) {
+	g := cube(16)
+	for x := 0; x < 16; x++ {
+		g.Delete(Pos{x, 0, 0})
+	}
+	g.Delete(Pos{0, 0, 0})
+	g.Write(Pos{3, 0, 0}, palette.Color{R: 9}, "stone")
+	if v, ok := g.Get(Pos{3, 0, 0}); !ok || v.BlockID != "stone" || g.Len() != 16*16*16-15 {
+		t.Errorf("rewritten voxel %v %v len %d", v, ok, g.Len())
+	}
+	if v, _ := g.Get(Pos{15, 15, 15}); v.Color != (palette.Color{R: 15, G: 15, B: 15}) {
+		t.Errorf("last voxel color %v", v.Color)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	small := cube(8).Downsample(4)
+	if small.Len() != 8 {
+		t.Fatalf("len %d, want 8", small.Len())
+	}
+	lo, hi := small.Bounds()
+	if lo != (Pos{0, 0, 0}) || hi != (Pos{1, 1, 1}) {
+		t.Errorf("bounds %v %v", lo, hi)
+	}
+}
+
+func BenchmarkRemoveHidden(b *testing.B) {
+	for range b.N {
+		b.StopTimer()
+		g := cube(64)
+		b.StartTimer()
+		g.RemoveHidden()
+	}
+}
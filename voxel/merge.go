@@ -0,0 +1,195 @@
+package voxel
+
+import (
+	"github.com/aatomu/model2minecraft/colormatch"
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// How overlapping writes to the same voxel are merged
+type MergePolicy int
+
+const (
+	FirstWins     MergePolicy = iota // Keep the first sample
+	LastWins                         // Keep the last sample
+	MajorityVote                     // Most written block id(or Grid.Vote, color when no block id)
+	AverageColor                     // Average color of all samples
+	LabAverage                       // Average color of all samples in Lab
+	LargestArea                      // Sample with the largest surface area inside the voxel
+	OutwardNormal                    // Sample facing the exterior most, settled by Grid.Resolve
+)
+
+// Surface sample written to a voxel
+type Sample struct {
+	Position Position // World position
+	Color    palette.Color
+	BlockID  string     // "": assigned later from the merged color
+	Area     float64    // Surface area inside the voxel(LargestArea)
+	Normal   [3]float64 // Unit surface normal(OutwardNormal), zero: unknown
+}
+
+type vote struct {
+	color   palette.Color
+	blockID string
+}
+
+type candidate struct {
+	color   palette.Color
+	blockID string
+	normal  [3]float64
+}
+
+type cell struct {
+	color   palette.Color
+	blockID string
+	n       uint32
+	// AverageColor
+	sum [3]uint32
+	// LabAverage
+	lab [3]float64
+	// MajorityVote
+	votes map[vote]uint32
+	best  uint32
+	// LargestArea
+	area float64
+	// OutwardNormal
+	candidates []candidate
+}
+
+// voteKey: MajorityVote key of samples without block id, nil: color
+func (c *cell) write(policy MergePolicy, s Sample, voteKey func(palette.Color) string) {
+	c.n++
+	switch policy {
+	case FirstWins:
+		if c.n == 1 {
+			c.color, c.blockID = s.Color, s.BlockID
+		}
+
+	case LastWins:
+		c.color, c.blockID = s.Color, s.BlockID
+
+	case MajorityVote:
+		// Voted block ids of colors are not kept, blocks are matched after shading
+		key := vote{blockID: s.BlockID}
+		switch {
+		case s.BlockID != "":
+		case voteKey != nil:
+			key.blockID = voteKey(s.Color)
+		default:
+			key.color = s.Color
+		}
+		if c.votes == nil {
+			c.votes = map[vote]uint32{}
+		}
+		c.votes[key]++
+		// Ties keep the earlier winner
+		if count := c.votes[key]; count > c.best {
+			c.best = count
+			c.color, c.blockID = s.Color, s.BlockID
+		}
+
+	case AverageColor:
+		c.sum[0] += uint32(s.Color.R)
+		c.sum[1] += uint32(s.Color.G)
+		c.sum[2] += uint32(s.Color.B)
+		c.color = palette.Color{
+			R: uint8((c.sum[0] + c.n/2) / c.n),
+			G: uint8((c.sum[1] + c.n/2) / c.n),
+			B: uint8((c.sum[2] + c.n/2) / c.n),
+		}
+		// Explicit block id is kept, otherwise assigned from the averaged color
+		if s.BlockID != "" {
+			c.blockID = s.BlockID
+		}
+
+	case LabAverage:
+		l, a, b := colormatch.RGBToLab(s.Color)
+		c.lab[0] += l
+		c.lab[1] += a
+		c.lab[2] += b
+		n := float64(c.n)
+		c.color = colormatch.LabToRGB(c.lab[0]/n, c.lab[1]/n, c.lab[2]/n)
+		if s.BlockID != "" {
+			c.blockID = s.BlockID
+		}
+
+	case LargestArea:
+		// Ties keep the earlier sample
+		if c.n == 1 || s.Area > c.area {
+			c.area = s.Area
+			c.color, c.blockID = s.Color, s.BlockID
+		}
+
+	case OutwardNormal:
+		// First sample until resolved
+		if c.n == 1 {
+			c.color, c.blockID = s.Color, s.BlockID
+		}
+		c.candidates = append(c.candidates, candidate{color: s.Color, blockID: s.BlockID, normal: s.Normal})
+	}
+}
+
+// Settle policies needing the whole grid
+//
+// OutwardNormal picks the sample whose normal faces the empty voxels reachable from outside.
+// Voxels without exterior direction keep the first sample.
+func (g *Grid) Resolve() {
+	if g.Policy != OutwardNormal || !g.pending() {
+		return
+	}
+	inside := g.FloodInterior()
+	exterior := func(p Pos) bool {
+		return !g.Has(p) && !inside(p)
+	}
+
+	var positions []Pos
+	g.Each(func(v Voxel) {
+		positions = append(positions, v.Pos)
+	})
+	for _, p := range positions {
+		c := g.lookup(p, false)
+		if len(c.candidates) < 2 {
+			c.candidates = nil
+			continue
+		}
+
+		var outward [3]float64
+		for x := -1; x <= 1; x++ {
+			for y := -1; y <= 1; y++ {
+				for z := -1; z <= 1; z++ {
+					if (x != 0 || y != 0 || z != 0) && exterior(p.Add(x, y, z)) {
+						outward[0] += float64(x)
+						outward[1] += float64(y)
+						outward[2] += float64(z)
+					}
+				}
+			}
+		}
+		if outward != [3]float64{} {
+			best := c.candidates[0]
+			bestDot := dot3(best.normal, outward)
+			for _, candidate := range c.candidates[1:] {
+				if d := dot3(candidate.normal, outward); d > bestDot {
+					best, bestDot = candidate, d
+				}
+			}
+			c.color, c.blockID = best.color, best.blockID
+		}
+		c.candidates = nil
+	}
+}
+
+// Some voxel has several unresolved candidates
+func (g *Grid) pending() bool {
+	for _, s := range g.sections {
+		for i := range s.cells {
+			if len(s.cells[i].candidates) > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dot3(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
@@ -0,0 +1,139 @@
+package voxel
+
+import (
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func TestMajorityVote(t *testing.T) {
+	g := NewGrid(1, MajorityVote)
+	g.Vote = func(c palette.Color) string {
+		if c.R > 100 {
+			return "light"
+		}
+		return "dark"
+	}
+	p := Pos{}
+	g.Write(p, palette.Color{R: 200}, "")
+	g.Write(p, palette.Color{R: 50}, "")
+	g.Write(p, palette.Color{R: 50}, "")
+	g.Write(p, palette.Color{R: 210}, "")
+	g.Write(p, palette.Color{R: 220}, "")
+
+	// Voted block id is not fixed, the block is matched later from the winning color
+	v, _ := g.Get(p)
+	if v.Color != (palette.Color{R: 220}) || v.BlockID != "" {
+		t.Errorf("voxel %v, want color R:220 without block id", v)
+	}
+
+	// Explicit block ids win as blocks
+	q := Pos{X: 1}
+	g.Write(q, palette.Color{R: 200}, "")
+	g.Write(q, palette.Color{R: 1}, "stone")
+	g.Write(q, palette.Color{R: 2}, "stone")
+	if v, _ := g.Get(q); v.BlockID != "stone" {
+		t.Errorf("voxel %v, want stone", v)
+	}
+}
+
+func TestOccludeMajorityVote(t *testing.T) {
+	g := NewGrid(1, MajorityVote)
+	g.Vote = func(c palette.Color) string {
+		if c.R > 100 {
+			return "light"
+		}
+		return "dark"
+	}
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			for z := -2; z <= 0; z++ {
+				g.Write(Pos{x, y, z}, palette.Color{R: 120}, "")
+			}
+		}
+	}
+	// Inner corner next to a wall on the slab is occluded
+	for y := 0; y < 5; y++ {
+		for z := 1; z <= 2; z++ {
+			g.Write(Pos{0, y, z}, palette.Color{R: 120}, "")
+		}
+	}
+	g.Occlude(Shading{Occlusion: true, Radius: 1, Strength: 0.5})
+	g.AssignBlocks(g.Vote)
+
+	if v, _ := g.Get(Pos{1, 2, 0}); v.BlockID != "dark" {
+		t.Errorf("occluded voxel %v, want dark", v)
+	}
+	if v, _ := g.Get(Pos{4, 2, 0}); v.BlockID != "light" {
+		t.Errorf("open voxel %v, want light", v)
+	}
+}
+
+func TestAverageColor(t *testing.T) {
+	tests := []struct {
+		policy MergePolicy
+		lo, hi uint8 // Gray range of black + white
+	}{
+		{AverageColor, 128, 128},
+		{LabAverage, 46, 48}, // L* 50, colormatch.RGBToLab has no sRGB gamma
+	}
+	for _, tt := range tests {
+		g := NewGrid(1, tt.policy)
+		g.Write(Pos{}, palette.Color{}, "")
+		g.Write(Pos{}, palette.Color{R: 255, G: 255, B: 255}, "stone")
+		g.Write(Pos{}, palette.Color{}, "")
+		g.Write(Pos{}, palette.Color{R: 255, G: 255, B: 255}, "")
+		v, _ := g.Get(Pos{})
+		if v.Color.R < tt.lo || v.Color.R > tt.hi || v.Color.G != v.Color.R || v.Color.B != v.Color.R {
+			t.Errorf("policy %d: color %v, want gray %d-%d", tt.policy, v.Color, tt.lo, tt.hi)
+		}
+		// Explicit block id is kept
+		if v.BlockID != "stone" {
+			t.Errorf("policy %d: block %q, want stone", tt.policy, v.BlockID)
+		}
+	}
+}
+
+func TestLargestArea(t *testing.T) {
+	g := NewGrid(1, LargestArea)
+	for i, area := range []float64{0.1, 0.5, 0.5, 0.2} {
+		g.WriteSample(Sample{Color: palette.Color{R: uint8(i)}, Area: area})
+	}
+	// Ties keep the earlier sample
+	if v, _ := g.Get(Pos{}); v.Color.R != 1 {
+		t.Errorf("color %v, want sample 1", v.Color)
+	}
+}
+
+func TestResolveOutwardNormal(t *testing.T) {
+	// Hollow 5³ box
+	g := NewGrid(1, OutwardNormal)
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			for z := 0; z < 5; z++ {
+				if x == 0 || x == 4 || y == 0 || y == 4 || z == 0 || z == 4 {
+					g.Write(Pos{x, y, z}, palette.Color{}, "")
+				}
+			}
+		}
+	}
+	// Top center: inward sample first, outward second
+	top := Position{X: 2, Y: 4, Z: 2}
+	g.WriteSample(Sample{Position: top, Color: palette.Color{R: 1}, Normal: [3]float64{0, -1, 0}})
+	g.WriteSample(Sample{Position: top, Color: palette.Color{R: 2}, Normal: [3]float64{0, 1, 0}})
+	// Bottom center: outward sample first
+	bottom := Position{X: 2, Y: 0, Z: 2}
+	g.WriteSample(Sample{Position: bottom, Color: palette.Color{G: 1}, Normal: [3]float64{0, -1, 0}})
+	g.WriteSample(Sample{Position: bottom, Color: palette.Color{G: 2}, Normal: [3]float64{0, 1, 0}})
+
+	if v, _ := g.Get(Pos{2, 4, 2}); v.Color.R != 0 {
+		t.Errorf("unresolved top %v, want first sample", v.Color)
+	}
+	g.Resolve()
+	if v, _ := g.Get(Pos{2, 4, 2}); v.Color.R != 2 {
+		t.Errorf("top %v, want the upward sample", v.Color)
+	}
+	if v, _ := g.Get(Pos{2, 0, 2}); v.Color.G != 1 {
+		t.Errorf("bottom %v, want the downward sample", v.Color)
+	}
+}
@@ -0,0 +1,81 @@
+package voxel
+
+import (
+	"math"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Structural pass mode
+type PhysicsMode int
+
+const (
+	PhysicsIgnore  PhysicsMode = iota // Output blocks as is
+	PhysicsReplace                    // Replace unsupported blocks with the nearest color solid block
+	PhysicsSupport                    // Insert support block under unsupported blocks
+)
+
+// Make sure every gravity/support/fluid block has a block underneath.
+//
+// Support is checked between Minecraft blocks(rounded world positions), voxels of a Spacing < 1 grid
+// sharing a block don't hold each other.
+// nearestSolid replaces blocks which can't be held, supportBlockID is inserted by PhysicsSupport.
+// Block ids must be assigned.
+func (g *Grid) ApplyPhysics(mode PhysicsMode, supportBlockID string, nearestSolid func(c palette.Color) string) {
+	if mode == PhysicsIgnore {
+		return
+	}
+
+	// Grid coordinates in each block
+	blocks := map[Pos][]Pos{}
+	voxels := g.Voxels()
+	for _, v := range voxels {
+		b := blockPos(v.Position)
+		blocks[b] = append(blocks[b], v.Pos)
+	}
+	supported := func(b Pos) (supported, occupied bool) {
+		for _, p := range blocks[b] {
+			if v, ok := g.Get(p); ok && palette.CanSupport(v.BlockID) {
+				return true, true
+			}
+		}
+		return false, len(blocks[b]) > 0
+	}
+
+	// Lower blocks are settled first
+	for _, v := range voxels {
+		if palette.PhysicsOf(v.BlockID) == palette.Solid {
+			continue
+		}
+
+		below := blockPos(v.Position).Add(0, -1, 0)
+		ok, hasBelow := supported(below)
+		if ok {
+			continue
+		}
+
+		switch mode {
+		case PhysicsReplace:
+			g.SetBlock(v.Pos, nearestSolid(v.Color))
+
+		case PhysicsSupport:
+			if hasBelow {
+				// Below is taken by a block that can't hold this one
+				g.SetBlock(v.Pos, nearestSolid(v.Color))
+				continue
+			}
+			p := g.ToPos(Position{X: float64(below.X), Y: float64(below.Y), Z: float64(below.Z)})
+			g.Put(p, v.Color, supportBlockID)
+			blocks[below] = append(blocks[below], p)
+		}
+	}
+}
+
+// Minecraft block containing world position
+func blockPos(p Position) Pos {
+	return Pos{
+		X: int(math.Round(p.X)),
+		Y: int(math.Round(p.Y)),
+		Z: int(math.Round(p.Z)),
+	}
+}
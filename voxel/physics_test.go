@@ -0,0 +1,87 @@
+package voxel
+
+import (
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func nearestSolid(c palette.Color) string {
+	return "smooth_sandstone"
+}
+
+func TestApplyPhysics(t *testing.T) {
+	build := func() *Grid {
+		g := NewGrid(1, FirstWins)
+		g.Put(Pos{X: 0, Y: 5}, palette.Color{}, "sand")           // floating
+		g.Put(Pos{X: 1, Y: 5}, palette.Color{}, "sand")           // on stone
+		g.Put(Pos{X: 1, Y: 4}, palette.Color{}, "stone")          //
+		g.Put(Pos{X: 2, Y: 5}, palette.Color{}, "red_carpet")     // on carpet
+		g.Put(Pos{X: 2, Y: 4}, palette.Color{}, "white_carpet")   // floating
+		g.Put(Pos{X: 3, Y: 5}, palette.Color{}, "white_concrete") // solid
+		return g
+	}
+
+	tests := []struct {
+		mode PhysicsMode
+		want map[Pos]string
+	}{
+		{PhysicsIgnore, map[Pos]string{
+			{X: 0, Y: 5}: "sand",
+			{X: 2, Y: 5}: "red_carpet",
+			{X: 2, Y: 4}: "white_carpet",
+		}},
+		{PhysicsReplace, map[Pos]string{
+			{X: 0, Y: 5}: "smooth_sandstone",
+			{X: 1, Y: 5}: "sand",
+			{X: 2, Y: 5}: "red_carpet", // held by the replaced carpet
+			{X: 2, Y: 4}: "smooth_sandstone",
+		}},
+		{PhysicsSupport, map[Pos]string{
+			{X: 0, Y: 5}: "sand",
+			{X: 0, Y: 4}: "stone",
+			{X: 2, Y: 5}: "smooth_sandstone", // carpet can't hold carpet
+			{X: 2, Y: 4}: "white_carpet",
+			{X: 2, Y: 3}: "stone",
+		}},
+	}
+	for _, tt := range tests {
+		g := build()
+		g.ApplyPhysics(tt.mode, "stone", nearestSolid)
+		for p, want := range tt.want {
+			if v, _ := g.Get(p); v.BlockID != want {
+				t.Errorf("mode %d %v: %q, want %q", tt.mode, p, v.BlockID, want)
+			}
+		}
+	}
+}
+
+func TestApplyPhysicsSupportTaken(t *testing.T) {
+	// Below is taken by a block that can't hold sand, sand is replaced instead of becoming the support block
+	g := NewGrid(1, FirstWins)
+	g.Put(Pos{Y: 1}, palette.Color{}, "sand")
+	g.Put(Pos{Y: 0}, palette.Color{}, "water")
+	g.Put(Pos{Y: -1}, palette.Color{}, "stone")
+	g.ApplyPhysics(PhysicsSupport, "stone", nearestSolid)
+	if v, _ := g.Get(Pos{Y: 1}); v.BlockID != "smooth_sandstone" {
+		t.Errorf("sand above water: %q, want smooth_sandstone", v.BlockID)
+	}
+}
+
+func TestApplyPhysicsSubBlock(t *testing.T) {
+	// Voxels of half a block: the lower half doesn't hold the upper half of the same block
+	g := NewGrid(0.5, FirstWins)
+	g.Put(Pos{Y: 3}, palette.Color{}, "sand") // y 1.5 -> block 2
+	g.Put(Pos{Y: 2}, palette.Color{}, "sand") // y 1.0 -> block 1
+	g.Put(Pos{Y: 1}, palette.Color{}, "sand") // y 0.5 -> block 1(rounded up)
+	g.ApplyPhysics(PhysicsSupport, "stone", nearestSolid)
+
+	if g.Len() != 4 {
+		t.Fatalf("%d voxels, want 4", g.Len())
+	}
+	// One support block under block 1
+	v, ok := g.Get(g.ToPos(Position{Y: 0}))
+	if !ok || v.BlockID != "stone" {
+		t.Errorf("block 0: %v %t, want stone", v, ok)
+	}
+}
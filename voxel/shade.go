@@ -0,0 +1,82 @@
+package voxel
+
+import (
+	"math"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+// Lighting baked into colors before block matching
+type Shading struct {
+	Directional bool       // Light by face normal(mesh sources)
+	Direction   [3]float64 // Direction the light travels, e.g. {-1, -2, -1}: from upper +X+Z
+	Ambient     float64    // Brightness of unlit faces 0..1
+	Occlusion   bool       // Ambient occlusion on the grid(all sources)
+	Radius      int        // Occlusion search radius in voxels
+	Strength    float64    // Darkening of fully occluded voxels 0..1
+}
+
+// Color lit by directional light, zero normal is unchanged
+func (s Shading) Light(c palette.Color, normal [3]float64) palette.Color {
+	if !s.Directional || normal == [3]float64{} {
+		return c
+	}
+	d := s.Direction
+	length := math.Sqrt(dot3(d, d))
+	if length == 0 {
+		return c
+	}
+	lambert := math.Max(0, -dot3(normal, d)/length)
+	return scaleColor(c, s.Ambient+(1-s.Ambient)*lambert)
+}
+
+// Darken voxels by the exterior space around them
+//
+// Voxels on flat surfaces and edges are kept, creases and holes are darkened.
+func (g *Grid) Occlude(s Shading) {
+	if !s.Occlusion || s.Radius <= 0 || g.count == 0 {
+		return
+	}
+	inside := g.FloodInterior()
+	exterior := func(p Pos) bool {
+		return !g.Has(p) && !inside(p)
+	}
+
+	type shade struct {
+		pos    Pos
+		factor float64
+	}
+	var shades []shade
+	side := 2*s.Radius + 1
+	// Open neighbors of a voxel on a flat surface
+	flat := float64(s.Radius * side * side)
+	g.Each(func(v Voxel) {
+		open := 0
+		for x := -s.Radius; x <= s.Radius; x++ {
+			for y := -s.Radius; y <= s.Radius; y++ {
+				for z := -s.Radius; z <= s.Radius; z++ {
+					if (x != 0 || y != 0 || z != 0) && exterior(v.Pos.Add(x, y, z)) {
+						open++
+					}
+				}
+			}
+		}
+		occluded := 1 - math.Min(1, float64(open)/flat)
+		shades = append(shades, shade{pos: v.Pos, factor: 1 - s.Strength*occluded})
+	})
+
+	// Colors change after every neighborhood is read
+	for _, sh := range shades {
+		c := g.lookup(sh.pos, false)
+		c.color = scaleColor(c.color, sh.factor)
+	}
+}
+
+func scaleColor(c palette.Color, f float64) palette.Color {
+	f = math.Max(0, math.Min(1, f))
+	return palette.Color{
+		R: uint8(math.Round(float64(c.R) * f)),
+		G: uint8(math.Round(float64(c.G) * f)),
+		B: uint8(math.Round(float64(c.B) * f)),
+	}
+}
@@ -0,0 +1,70 @@
+package voxel
+
+import (
+	"testing"
+
+	"github.com/aatomu/model2minecraft/palette"
+)
+
+func TestLight(t *testing.T) {
+	s := Shading{Directional: true, Direction: [3]float64{0, -2, 0}, Ambient: 0.25}
+	white := palette.Color{R: 200, G: 200, B: 200}
+
+	tests := []struct {
+		normal [3]float64
+		want   uint8
+	}{
+		{[3]float64{0, 1, 0}, 200},     // facing the light
+		{[3]float64{0, -1, 0}, 50},     // ambient only
+		{[3]float64{1, 0, 0}, 50},      // grazing
+		{[3]float64{0.6, 0.8, 0}, 170}, // 0.25 + 0.75*0.8
+		{[3]float64{}, 200},            // unknown normal
+	}
+	for _, tt := range tests {
+		if c := s.Light(white, tt.normal); c.R != tt.want || c.G != tt.want || c.B != tt.want {
+			t.Errorf("normal %v: %v, want %d", tt.normal, c, tt.want)
+		}
+	}
+
+	// Up is brighter than down
+	up, down := s.Light(white, [3]float64{0, 1, 0}), s.Light(white, [3]float64{0, -1, 0})
+	if up.R <= down.R {
+		t.Errorf("up %v is not brighter than down %v", up, down)
+	}
+
+	s.Directional = false
+	if c := s.Light(white, [3]float64{0, -1, 0}); c != white {
+		t.Errorf("disabled light changed %v", c)
+	}
+}
+
+func TestOcclude(t *testing.T) {
+	// Thick floor with a wall on x=0
+	g := NewGrid(1, FirstWins)
+	gray := palette.Color{R: 100, G: 100, B: 100}
+	for x := 0; x < 7; x++ {
+		for y := -2; y <= 0; y++ {
+			for z := 0; z < 7; z++ {
+				g.Write(Pos{x, y, z}, gray, "")
+			}
+		}
+	}
+	for y := 1; y < 4; y++ {
+		for z := 0; z < 7; z++ {
+			g.Write(Pos{0, y, z}, gray, "")
+		}
+	}
+	g.Occlude(Shading{Occlusion: true, Radius: 1, Strength: 0.5})
+
+	// Flat floor is kept, the crease next to the wall is darkened
+	if v, _ := g.Get(Pos{4, 0, 3}); v.Color != gray {
+		t.Errorf("flat voxel %v, want unchanged", v.Color)
+	}
+	if v, _ := g.Get(Pos{1, 0, 3}); v.Color.R >= gray.R {
+		t.Errorf("crease voxel %v, want darker", v.Color)
+	}
+	// Outer edge of the floor is more open than flat
+	if v, _ := g.Get(Pos{6, 0, 3}); v.Color != gray {
+		t.Errorf("edge voxel %v, want unchanged", v.Color)
+	}
+}